@@ -46,6 +46,18 @@ func main() {
 		os.Exit(0)
 	}
 
+	// Only validate configuration and exit
+	if args.ValidateConfigFile != "" || args.ValidateOptions != "" {
+		if errs := worker.ValidateConfig(args); len(errs) > 0 {
+			for _, err := range errs {
+				fmt.Fprintln(os.Stderr, err)
+			}
+			os.Exit(1)
+		}
+		fmt.Println("configuration is valid")
+		os.Exit(0)
+	}
+
 	// Assert that the version is known
 	if version.Undefined() {
 		klog.Warningf("version not set! Set -ldflags \"-X sigs.k8s.io/node-feature-discovery/pkg/version.version=`git describe --tags --dirty --always`\" during build or run.")
@@ -108,17 +120,39 @@ func initFlags(flagset *flag.FlagSet) (*worker.Args, *worker.ConfigOverrideArgs)
 		"Certificate used for authenticating connections")
 	flagset.StringVar(&args.ConfigFile, "config", "/etc/kubernetes/node-feature-discovery/nfd-worker.conf",
 		"Config file to use.")
+	flagset.Var(&args.ExtraLabelNs, "extra-label-ns",
+		"Comma separated list of allowed extra label namespaces. Only has effect in standalone mode.")
+	flagset.StringVar(&args.Instance, "instance", "",
+		"Instance name. Used to separate annotation namespaces for multiple parallel deployments. "+
+			"Only has effect in standalone mode.")
 	flagset.StringVar(&args.KeyFile, "key-file", "",
 		"Private key matching -cert-file")
+	flagset.StringVar(&args.Kubeconfig, "kubeconfig", "",
+		"Kubeconfig to use. Only has effect in standalone mode.")
+	flagset.IntVar(&args.MetricsPort, "metrics", 8081,
+		"Port on which to expose metrics. A non-positive value disables the metrics server.")
 	flagset.BoolVar(&args.Oneshot, "oneshot", false,
 		"Do not publish feature labels")
 	flagset.StringVar(&args.Options, "options", "",
 		"Specify config options from command line. Config options are specified "+
 			"in the same format as in the config file (i.e. json or yaml). These options")
+	flagset.StringVar(&args.PluginDir, "plugin-dir", "/var/lib/node-feature-discovery/plugins",
+		"Directory where to look for source plugin sockets. Empty value disables source plugins.")
+	flagset.Var(&args.ResourceLabels, "resource-labels",
+		"Comma separated list of labels to be exposed as extended resources. Only has effect in standalone mode.")
 	flagset.StringVar(&args.Server, "server", "localhost:8080",
 		"NFD server address to connecto to.")
 	flagset.StringVar(&args.ServerNameOverride, "server-name-override", "",
 		"Hostname expected from server certificate, useful in testing")
+	flagset.BoolVar(&args.Standalone, "standalone", false,
+		"Run in standalone mode, i.e. update the node object in the Kubernetes API directly "+
+			"instead of sending labeling requests to nfd-master.")
+	flagset.StringVar(&args.ValidateConfigFile, "validate-config", "",
+		"Validate the given config file (and -options, if specified) and exit. "+
+			"Unknown config options are treated as errors.")
+	flagset.StringVar(&args.ValidateOptions, "validate-options", "",
+		"Validate the given config options (and -options, if specified) and exit. "+
+			"Unknown config options are treated as errors.")
 
 	initKlogFlags(flagset, args)
 
@@ -43,6 +43,19 @@ func TestParseArgs(t *testing.T) {
 			})
 		})
 
+		Convey("When standalone mode is specified", func() {
+			args := parseArgs(flags,
+				"-standalone",
+				"-extra-label-ns=vendor.io",
+				"-resource-labels=vendor.io/feature-1")
+
+			Convey("standalone args should be set", func() {
+				So(args.Standalone, ShouldBeTrue)
+				So(args.ExtraLabelNs, ShouldResemble, utils.StringSetVal{"vendor.io": struct{}{}})
+				So(args.ResourceLabels, ShouldResemble, utils.StringSetVal{"vendor.io/feature-1": struct{}{}})
+			})
+		})
+
 		Convey("When all override args are specified", func() {
 			args := parseArgs(flags,
 				"-no-publish",
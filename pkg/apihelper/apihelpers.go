@@ -27,26 +27,26 @@ import (
 // APIHelpers represents a set of API helpers for Kubernetes
 type APIHelpers interface {
 	// GetClient returns a client
-	GetClient() (*k8sclient.Clientset, error)
+	GetClient() (k8sclient.Interface, error)
 
 	// GetNode returns the Kubernetes node on which this container is running.
-	GetNode(*k8sclient.Clientset, string) (*api.Node, error)
+	GetNode(k8sclient.Interface, string) (*api.Node, error)
 
 	// GetNodes returns all the nodes in the cluster
-	GetNodes(*k8sclient.Clientset) (*api.NodeList, error)
+	GetNodes(k8sclient.Interface) (*api.NodeList, error)
 
 	// UpdateNode updates the node via the API server using a client.
-	UpdateNode(*k8sclient.Clientset, *api.Node) error
+	UpdateNode(k8sclient.Interface, *api.Node) error
 
 	// PatchNode updates the node object via the API server using a client.
-	PatchNode(*k8sclient.Clientset, string, []JsonPatch) error
+	PatchNode(k8sclient.Interface, string, []JsonPatch) error
 
 	// PatchNodeStatus updates the node status via the API server using a client.
-	PatchNodeStatus(*k8sclient.Clientset, string, []JsonPatch) error
+	PatchNodeStatus(k8sclient.Interface, string, []JsonPatch) error
 
 	// GetTopologyClient returns a topologyclientset
 	GetTopologyClient() (*topologyclientset.Clientset, error)
 
 	// GetPod returns the Kubernetes pod in a namepace with a name.
-	GetPod(*k8sclient.Clientset, string, string) (*api.Pod, error)
+	GetPod(k8sclient.Interface, string, string) (*api.Pod, error)
 }
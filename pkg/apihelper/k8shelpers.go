@@ -32,9 +32,15 @@ import (
 // Implements APIHelpers
 type K8sHelpers struct {
 	Kubeconfig *restclient.Config
+	// Client is returned by GetClient, if set, instead of creating a new
+	// client from Kubeconfig
+	Client k8sclient.Interface
 }
 
-func (h K8sHelpers) GetClient() (*k8sclient.Clientset, error) {
+func (h K8sHelpers) GetClient() (k8sclient.Interface, error) {
+	if h.Client != nil {
+		return h.Client, nil
+	}
 	clientset, err := k8sclient.NewForConfig(h.Kubeconfig)
 	if err != nil {
 		return nil, err
@@ -50,7 +56,7 @@ func (h K8sHelpers) GetTopologyClient() (*topologyclientset.Clientset, error) {
 	return topologyClient, nil
 }
 
-func (h K8sHelpers) GetNode(cli *k8sclient.Clientset, nodeName string) (*api.Node, error) {
+func (h K8sHelpers) GetNode(cli k8sclient.Interface, nodeName string) (*api.Node, error) {
 	// Get the node object using node name
 	node, err := cli.CoreV1().Nodes().Get(context.TODO(), nodeName, meta_v1.GetOptions{})
 	if err != nil {
@@ -60,11 +66,11 @@ func (h K8sHelpers) GetNode(cli *k8sclient.Clientset, nodeName string) (*api.Nod
 	return node, nil
 }
 
-func (h K8sHelpers) GetNodes(cli *k8sclient.Clientset) (*api.NodeList, error) {
+func (h K8sHelpers) GetNodes(cli k8sclient.Interface) (*api.NodeList, error) {
 	return cli.CoreV1().Nodes().List(context.TODO(), meta_v1.ListOptions{})
 }
 
-func (h K8sHelpers) UpdateNode(c *k8sclient.Clientset, n *api.Node) error {
+func (h K8sHelpers) UpdateNode(c k8sclient.Interface, n *api.Node) error {
 	// Send the updated node to the apiserver.
 	_, err := c.CoreV1().Nodes().Update(context.TODO(), n, meta_v1.UpdateOptions{})
 	if err != nil {
@@ -74,7 +80,7 @@ func (h K8sHelpers) UpdateNode(c *k8sclient.Clientset, n *api.Node) error {
 	return nil
 }
 
-func (h K8sHelpers) PatchNode(c *k8sclient.Clientset, nodeName string, patches []JsonPatch) error {
+func (h K8sHelpers) PatchNode(c k8sclient.Interface, nodeName string, patches []JsonPatch) error {
 	if len(patches) > 0 {
 		data, err := json.Marshal(patches)
 		if err == nil {
@@ -85,7 +91,7 @@ func (h K8sHelpers) PatchNode(c *k8sclient.Clientset, nodeName string, patches [
 	return nil
 }
 
-func (h K8sHelpers) PatchNodeStatus(c *k8sclient.Clientset, nodeName string, patches []JsonPatch) error {
+func (h K8sHelpers) PatchNodeStatus(c k8sclient.Interface, nodeName string, patches []JsonPatch) error {
 	if len(patches) > 0 {
 		data, err := json.Marshal(patches)
 		if err == nil {
@@ -97,7 +103,7 @@ func (h K8sHelpers) PatchNodeStatus(c *k8sclient.Clientset, nodeName string, pat
 
 }
 
-func (h K8sHelpers) GetPod(cli *k8sclient.Clientset, namespace string, podName string) (*api.Pod, error) {
+func (h K8sHelpers) GetPod(cli k8sclient.Interface, namespace string, podName string) (*api.Pod, error) {
 	// Get the node object using pod name
 	pod, err := cli.CoreV1().Pods(namespace).Get(context.TODO(), podName, meta_v1.GetOptions{})
 	if err != nil {
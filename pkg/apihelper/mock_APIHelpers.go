@@ -17,15 +17,15 @@ type MockAPIHelpers struct {
 }
 
 // GetClient provides a mock function with given fields:
-func (_m *MockAPIHelpers) GetClient() (*kubernetes.Clientset, error) {
+func (_m *MockAPIHelpers) GetClient() (kubernetes.Interface, error) {
 	ret := _m.Called()
 
-	var r0 *kubernetes.Clientset
-	if rf, ok := ret.Get(0).(func() *kubernetes.Clientset); ok {
+	var r0 kubernetes.Interface
+	if rf, ok := ret.Get(0).(func() kubernetes.Interface); ok {
 		r0 = rf()
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*kubernetes.Clientset)
+			r0 = ret.Get(0).(kubernetes.Interface)
 		}
 	}
 
@@ -40,11 +40,11 @@ func (_m *MockAPIHelpers) GetClient() (*kubernetes.Clientset, error) {
 }
 
 // GetNode provides a mock function with given fields: _a0, _a1
-func (_m *MockAPIHelpers) GetNode(_a0 *kubernetes.Clientset, _a1 string) (*v1.Node, error) {
+func (_m *MockAPIHelpers) GetNode(_a0 kubernetes.Interface, _a1 string) (*v1.Node, error) {
 	ret := _m.Called(_a0, _a1)
 
 	var r0 *v1.Node
-	if rf, ok := ret.Get(0).(func(*kubernetes.Clientset, string) *v1.Node); ok {
+	if rf, ok := ret.Get(0).(func(kubernetes.Interface, string) *v1.Node); ok {
 		r0 = rf(_a0, _a1)
 	} else {
 		if ret.Get(0) != nil {
@@ -53,7 +53,7 @@ func (_m *MockAPIHelpers) GetNode(_a0 *kubernetes.Clientset, _a1 string) (*v1.No
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*kubernetes.Clientset, string) error); ok {
+	if rf, ok := ret.Get(1).(func(kubernetes.Interface, string) error); ok {
 		r1 = rf(_a0, _a1)
 	} else {
 		r1 = ret.Error(1)
@@ -63,11 +63,11 @@ func (_m *MockAPIHelpers) GetNode(_a0 *kubernetes.Clientset, _a1 string) (*v1.No
 }
 
 // GetNodes provides a mock function with given fields: _a0
-func (_m *MockAPIHelpers) GetNodes(_a0 *kubernetes.Clientset) (*v1.NodeList, error) {
+func (_m *MockAPIHelpers) GetNodes(_a0 kubernetes.Interface) (*v1.NodeList, error) {
 	ret := _m.Called(_a0)
 
 	var r0 *v1.NodeList
-	if rf, ok := ret.Get(0).(func(*kubernetes.Clientset) *v1.NodeList); ok {
+	if rf, ok := ret.Get(0).(func(kubernetes.Interface) *v1.NodeList); ok {
 		r0 = rf(_a0)
 	} else {
 		if ret.Get(0) != nil {
@@ -76,7 +76,7 @@ func (_m *MockAPIHelpers) GetNodes(_a0 *kubernetes.Clientset) (*v1.NodeList, err
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*kubernetes.Clientset) error); ok {
+	if rf, ok := ret.Get(1).(func(kubernetes.Interface) error); ok {
 		r1 = rf(_a0)
 	} else {
 		r1 = ret.Error(1)
@@ -86,11 +86,11 @@ func (_m *MockAPIHelpers) GetNodes(_a0 *kubernetes.Clientset) (*v1.NodeList, err
 }
 
 // GetPod provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockAPIHelpers) GetPod(_a0 *kubernetes.Clientset, _a1 string, _a2 string) (*v1.Pod, error) {
+func (_m *MockAPIHelpers) GetPod(_a0 kubernetes.Interface, _a1 string, _a2 string) (*v1.Pod, error) {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 *v1.Pod
-	if rf, ok := ret.Get(0).(func(*kubernetes.Clientset, string, string) *v1.Pod); ok {
+	if rf, ok := ret.Get(0).(func(kubernetes.Interface, string, string) *v1.Pod); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		if ret.Get(0) != nil {
@@ -99,7 +99,7 @@ func (_m *MockAPIHelpers) GetPod(_a0 *kubernetes.Clientset, _a1 string, _a2 stri
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(*kubernetes.Clientset, string, string) error); ok {
+	if rf, ok := ret.Get(1).(func(kubernetes.Interface, string, string) error); ok {
 		r1 = rf(_a0, _a1, _a2)
 	} else {
 		r1 = ret.Error(1)
@@ -132,11 +132,11 @@ func (_m *MockAPIHelpers) GetTopologyClient() (*versioned.Clientset, error) {
 }
 
 // PatchNode provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockAPIHelpers) PatchNode(_a0 *kubernetes.Clientset, _a1 string, _a2 []JsonPatch) error {
+func (_m *MockAPIHelpers) PatchNode(_a0 kubernetes.Interface, _a1 string, _a2 []JsonPatch) error {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*kubernetes.Clientset, string, []JsonPatch) error); ok {
+	if rf, ok := ret.Get(0).(func(kubernetes.Interface, string, []JsonPatch) error); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
@@ -146,11 +146,11 @@ func (_m *MockAPIHelpers) PatchNode(_a0 *kubernetes.Clientset, _a1 string, _a2 [
 }
 
 // PatchNodeStatus provides a mock function with given fields: _a0, _a1, _a2
-func (_m *MockAPIHelpers) PatchNodeStatus(_a0 *kubernetes.Clientset, _a1 string, _a2 []JsonPatch) error {
+func (_m *MockAPIHelpers) PatchNodeStatus(_a0 kubernetes.Interface, _a1 string, _a2 []JsonPatch) error {
 	ret := _m.Called(_a0, _a1, _a2)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*kubernetes.Clientset, string, []JsonPatch) error); ok {
+	if rf, ok := ret.Get(0).(func(kubernetes.Interface, string, []JsonPatch) error); ok {
 		r0 = rf(_a0, _a1, _a2)
 	} else {
 		r0 = ret.Error(0)
@@ -160,11 +160,11 @@ func (_m *MockAPIHelpers) PatchNodeStatus(_a0 *kubernetes.Clientset, _a1 string,
 }
 
 // UpdateNode provides a mock function with given fields: _a0, _a1
-func (_m *MockAPIHelpers) UpdateNode(_a0 *kubernetes.Clientset, _a1 *v1.Node) error {
+func (_m *MockAPIHelpers) UpdateNode(_a0 kubernetes.Interface, _a1 *v1.Node) error {
 	ret := _m.Called(_a0, _a1)
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*kubernetes.Clientset, *v1.Node) error); ok {
+	if rf, ok := ret.Get(0).(func(kubernetes.Interface, *v1.Node) error); ok {
 		r0 = rf(_a0, _a1)
 	} else {
 		r0 = ret.Error(0)
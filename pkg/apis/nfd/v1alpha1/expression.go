@@ -456,6 +456,11 @@ func (m *MatchExpressionSet) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON implements the Marshaler interface of "encoding/json".
+func (m *MatchExpressionSet) MarshalJSON() ([]byte, error) {
+	return json.Marshal(m.Expressions)
+}
+
 // UnmarshalJSON implements the Unmarshaler interface of "encoding/json".
 func (m *MatchOp) UnmarshalJSON(data []byte) error {
 	var raw string
@@ -0,0 +1,209 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hwids
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Database holds the vendor, device and class names parsed from a pci.ids or
+// usb.ids formatted file.
+type Database struct {
+	vendors    map[string]string
+	devices    map[string]map[string]string
+	classes    map[string]string
+	subclasses map[string]map[string]string
+}
+
+type cacheEntry struct {
+	modTime time.Time
+	size    int64
+	db      *Database
+}
+
+var (
+	cache    = map[string]cacheEntry{}
+	cacheMtx sync.Mutex
+)
+
+// Parse reads an ids database from r. Only vendor, device, class and
+// subclass names are stored, other sections of the file are skipped.
+func Parse(r io.Reader) (*Database, error) {
+	db := &Database{
+		vendors:    make(map[string]string),
+		devices:    make(map[string]map[string]string),
+		classes:    make(map[string]string),
+		subclasses: make(map[string]map[string]string),
+	}
+
+	const (
+		sectionNone = iota
+		sectionVendor
+		sectionClass
+	)
+	section := sectionNone
+	parent := ""
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if len(strings.TrimSpace(line)) == 0 || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		switch {
+		case strings.HasPrefix(line, "\t\t"):
+			// Subsystems, interfaces and programming interfaces are not used
+		case strings.HasPrefix(line, "\t"):
+			id, name, ok := splitEntry(line[1:])
+			if !ok {
+				continue
+			}
+			switch section {
+			case sectionVendor:
+				db.devices[parent][id] = name
+			case sectionClass:
+				db.subclasses[parent][id] = name
+			}
+		case strings.HasPrefix(line, "C "):
+			id, name, ok := splitEntry(line[2:])
+			if !ok {
+				section = sectionNone
+				continue
+			}
+			section, parent = sectionClass, id
+			db.classes[id] = name
+			db.subclasses[id] = make(map[string]string)
+		default:
+			id, name, ok := splitEntry(line)
+			if !ok || len(id) != 4 || !isHex(id) {
+				// Some other section, e.g. the language or HID usage
+				// tables of usb.ids
+				section = sectionNone
+				continue
+			}
+			section, parent = sectionVendor, id
+			db.vendors[id] = name
+			if _, ok := db.devices[id]; !ok {
+				db.devices[id] = make(map[string]string)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// Load parses the ids database at path. Parsed databases are cached and only
+// re-read if the file has changed.
+func Load(path string) (*Database, error) {
+	stat, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cacheMtx.Lock()
+	defer cacheMtx.Unlock()
+
+	if e, ok := cache[path]; ok && e.modTime.Equal(stat.ModTime()) && e.size == stat.Size() {
+		return e.db, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	db, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	cache[path] = cacheEntry{modTime: stat.ModTime(), size: stat.Size(), db: db}
+
+	return db, nil
+}
+
+// LoadFirst loads the first existing ids database of the given paths.
+func LoadFirst(paths ...string) (*Database, string, error) {
+	for _, path := range paths {
+		db, err := Load(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, path, err
+		}
+		return db, path, nil
+	}
+	return nil, "", fmt.Errorf("no ids database found in %s", strings.Join(paths, ", "))
+}
+
+// Vendor returns the name of a vendor, or an empty string if not found.
+func (db *Database) Vendor(vendor string) string {
+	return db.vendors[strings.ToLower(vendor)]
+}
+
+// Device returns the name of a device of a vendor, or an empty string if not
+// found.
+func (db *Database) Device(vendor, device string) string {
+	return db.devices[strings.ToLower(vendor)][strings.ToLower(device)]
+}
+
+// Class returns the name of a device class. The code is the (two digit) base
+// class, optionally followed by the (two digit) subclass. The subclass name is
+// preferred, falling back to the name of the base class.
+func (db *Database) Class(code string) string {
+	code = strings.ToLower(code)
+	if len(code) < 2 {
+		return ""
+	}
+	if len(code) >= 4 {
+		if name, ok := db.subclasses[code[:2]][code[2:4]]; ok {
+			return name
+		}
+	}
+	return db.classes[code[:2]]
+}
+
+// splitEntry splits an ids database line into the id and name parts.
+func splitEntry(s string) (string, string, bool) {
+	split := strings.SplitN(s, " ", 2)
+	if len(split) != 2 {
+		return "", "", false
+	}
+	id, name := strings.ToLower(split[0]), strings.TrimSpace(split[1])
+	if id == "" || name == "" {
+		return "", "", false
+	}
+	return id, name, true
+}
+
+func isHex(s string) bool {
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789abcdef", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,167 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hwids
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testPciIds = `#
+# List of PCI ID's
+#
+8086  Intel Corporation
+	0007  82379AB
+	1572  Ethernet Controller X710 for 10GbE SFP+
+		1028 0000  Ethernet 10G X710 rNDC
+10de  NVIDIA Corporation
+	1eb8  TU104GL [Tesla T4]
+
+# List of known device classes, subclasses and programming interfaces
+C 02  Network controller
+	00  Ethernet controller
+	80  Network controller
+C 03  Display controller
+	00  VGA compatible controller
+		00  VGA controller
+	02  3D controller
+`
+
+const testUsbIds = `1d6b  Linux Foundation
+	0002  2.0 root hub
+C 03  Human Interface Device
+	01  Boot Interface Subclass
+		01  Keyboard
+C 09  Hub
+AT 0409  Keyboard
+HID 00  None
+L 0001  Arabic
+	01  Saudi Arabia
+`
+
+func TestParse(t *testing.T) {
+	db, err := Parse(strings.NewReader(testPciIds))
+	if err != nil {
+		t.Fatalf("failed to parse pci.ids: %v", err)
+	}
+	tcs := []struct{ got, want string }{
+		{db.Vendor("8086"), "Intel Corporation"},
+		{db.Vendor("10DE"), "NVIDIA Corporation"},
+		{db.Vendor("1234"), ""},
+		{db.Device("8086", "1572"), "Ethernet Controller X710 for 10GbE SFP+"},
+		{db.Device("10de", "1eb8"), "TU104GL [Tesla T4]"},
+		{db.Device("10de", "1572"), ""},
+		{db.Class("0300"), "VGA compatible controller"},
+		{db.Class("0302"), "3D controller"},
+		{db.Class("0301"), "Display controller"},
+		{db.Class("03"), "Display controller"},
+		{db.Class("0b40"), ""},
+		{db.Class("0"), ""},
+	}
+	for i, tc := range tcs {
+		if tc.got != tc.want {
+			t.Errorf("test case %d: expected %q, got %q", i, tc.want, tc.got)
+		}
+	}
+
+	db, err = Parse(strings.NewReader(testUsbIds))
+	if err != nil {
+		t.Fatalf("failed to parse usb.ids: %v", err)
+	}
+	tcs = []struct{ got, want string }{
+		{db.Vendor("1d6b"), "Linux Foundation"},
+		{db.Device("1d6b", "0002"), "2.0 root hub"},
+		{db.Class("03"), "Human Interface Device"},
+		{db.Class("09"), "Hub"},
+		{db.Vendor("0001"), ""},
+		{db.Device("0001", "01"), ""},
+	}
+	for i, tc := range tcs {
+		if tc.got != tc.want {
+			t.Errorf("test case %d: expected %q, got %q", i, tc.want, tc.got)
+		}
+	}
+}
+
+func TestLoadFirst(t *testing.T) {
+	tmpDir := t.TempDir()
+	path := filepath.Join(tmpDir, "pci.ids")
+	if err := os.WriteFile(path, []byte(testPciIds), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	db, found, err := LoadFirst(filepath.Join(tmpDir, "missing.ids"), path)
+	if err != nil {
+		t.Fatalf("failed to load ids database: %v", err)
+	}
+	if found != path {
+		t.Errorf("expected %q to be loaded, got %q", path, found)
+	}
+	if name := db.Vendor("8086"); name != "Intel Corporation" {
+		t.Errorf("unexpected vendor name %q", name)
+	}
+
+	// Unchanged file should be served from the cache
+	if db2, _ := Load(path); db2 != db {
+		t.Errorf("expected cached database to be returned")
+	}
+
+	if _, _, err := LoadFirst(filepath.Join(tmpDir, "missing.ids")); err == nil {
+		t.Errorf("expected an error when no database is found")
+	}
+}
+
+func TestFilePaths(t *testing.T) {
+	if paths := FilePaths("/custom/pci.ids", "/host-usr", "pci.ids"); len(paths) != 1 || paths[0] != "/custom/pci.ids" {
+		t.Errorf("unexpected paths %v", paths)
+	}
+	paths := FilePaths("", "/host-usr", "usb.ids")
+	if len(paths) != 4 || paths[0] != "/host-usr/share/hwdata/usb.ids" || paths[3] != "/usr/share/misc/usb.ids" {
+		t.Errorf("unexpected paths %v", paths)
+	}
+}
+
+func TestLabelFieldValue(t *testing.T) {
+	attrs := map[string]string{"vendor": "8086", "vendor_name": "Intel Corporation", "device": "0007"}
+	if v := LabelFieldValue(attrs, "vendor_name"); v != "Intel_Corporation" {
+		t.Errorf("unexpected vendor_name label value %q", v)
+	}
+	if v := LabelFieldValue(attrs, "device_name"); v != "0007" {
+		t.Errorf("expected fallback to device id, got %q", v)
+	}
+	if v := LabelFieldValue(attrs, "vendor"); v != "8086" {
+		t.Errorf("unexpected vendor label value %q", v)
+	}
+}
+
+func TestDeviceLabel(t *testing.T) {
+	attrs := map[string]string{
+		"class":       "0200",
+		"vendor":      "8086",
+		"vendor_name": "Intel Corporation",
+		"device":      "1592",
+		"device_name": "Ethernet Controller E810-C for QSFP",
+	}
+	if l := DeviceLabel(attrs, []string{"class", "vendor_name"}, 63); l != "0200_Intel_Corporation" {
+		t.Errorf("unexpected device label %q", l)
+	}
+	if l := DeviceLabel(attrs, []string{"vendor_name", "device_name"}, 40); l != "8086_1592" {
+		t.Errorf("expected fallback to ids for a too long label, got %q", l)
+	}
+}
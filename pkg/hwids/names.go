@@ -0,0 +1,114 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package hwids
+
+import (
+	"path/filepath"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+// NameAttrs are the device attributes resolved from an ids database
+var NameAttrs = []string{"class_name", "vendor_name", "device_name"}
+
+// nameAttrIDs maps name attributes to the attribute holding the
+// corresponding numerical id
+var nameAttrIDs = map[string]string{
+	"class_name":  "class",
+	"vendor_name": "vendor",
+	"device_name": "device",
+}
+
+// FilePaths returns the candidate locations of an ids database, e.g.
+// "pci.ids". If idsFile is non-empty it is the only candidate. Otherwise, the
+// host system (with its /usr directory at hostUsrDir) is searched first, then
+// the nfd image itself.
+func FilePaths(idsFile, hostUsrDir, name string) []string {
+	if idsFile != "" {
+		return []string{idsFile}
+	}
+	return []string{
+		filepath.Join(hostUsrDir, "share/hwdata", name),
+		filepath.Join(hostUsrDir, "share/misc", name),
+		filepath.Join("/usr/share/hwdata", name),
+		filepath.Join("/usr/share/misc", name),
+	}
+}
+
+// ResolveNames adds human-readable vendor, device and class names to the
+// attributes of devices, using the first existing ids database of the given
+// paths.
+func ResolveNames(devs []feature.InstanceFeature, paths []string) error {
+	db, path, err := LoadFirst(paths...)
+	if err != nil {
+		return err
+	}
+	klog.V(2).Infof("resolving device names from %s", path)
+
+	for _, dev := range devs {
+		attrs := dev.Attributes
+		names := map[string]string{
+			"class_name":  db.Class(attrs["class"]),
+			"vendor_name": db.Vendor(attrs["vendor"]),
+			"device_name": db.Device(attrs["vendor"], attrs["device"]),
+		}
+		for attr, name := range names {
+			if name != "" {
+				attrs[attr] = name
+			}
+		}
+	}
+	return nil
+}
+
+// LabelFieldValue returns the value of a device attribute to be used in
+// labels. Names are sanitized, falling back to the numerical id if the name
+// is not known.
+func LabelFieldValue(attrs map[string]string, attr string) string {
+	idAttr, ok := nameAttrIDs[attr]
+	if !ok {
+		return attrs[attr]
+	}
+	if v := utils.SanitizeLabelValue(attrs[attr]); v != "" {
+		return v
+	}
+	return attrs[idAttr]
+}
+
+// DeviceLabel returns the device specific part of a label name, i.e. the
+// label values of the given attributes joined with underscores. Numerical ids
+// are used in place of all names if the result would be longer than maxLen.
+func DeviceLabel(attrs map[string]string, fields []string, maxLen int) string {
+	values := make([]string, len(fields))
+	for i, attr := range fields {
+		values[i] = LabelFieldValue(attrs, attr)
+	}
+	if l := strings.Join(values, "_"); len(l) <= maxLen {
+		return l
+	}
+
+	for i, attr := range fields {
+		if idAttr, ok := nameAttrIDs[attr]; ok {
+			values[i] = attrs[idAttr]
+		}
+	}
+	return strings.Join(values, "_")
+}
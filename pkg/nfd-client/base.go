@@ -21,12 +21,14 @@ import (
 	"crypto/x509"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"os"
 	"time"
 
 	"golang.org/x/net/context"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
@@ -64,6 +66,32 @@ func init() {
 // NodeName returns the name of the k8s node we're running on.
 func NodeName() string { return nodeName }
 
+// NewBackoff returns a new backoff for retrying failed connections and
+// requests to nfd-master. The retry interval grows exponentially, with jitter,
+// from one second up to five minutes.
+func NewBackoff() wait.Backoff {
+	return wait.Backoff{
+		Duration: time.Second,
+		Factor:   2,
+		Jitter:   0.2,
+		Steps:    math.MaxInt32,
+		Cap:      5 * time.Minute,
+	}
+}
+
+// ScheduleRetry returns a channel triggering a new attempt of the failed
+// operation after a backoff period if err is non-nil. The backoff is reset on
+// success, in which case a nil channel is returned.
+func ScheduleRetry(err error, backoff *wait.Backoff, op string) <-chan time.Time {
+	if err == nil {
+		*backoff = NewBackoff()
+		return nil
+	}
+	d := backoff.Step()
+	klog.Errorf("failed to %s, retrying in %v: %v", op, d, err)
+	return time.After(d)
+}
+
 // Create new NfdWorker instance.
 func NewNfdBaseClient(args *Args) (NfdBaseClient, error) {
 	nfd := NfdBaseClient{args: *args}
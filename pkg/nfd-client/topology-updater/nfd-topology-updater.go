@@ -126,10 +126,18 @@ func (w *nfdTopologyUpdater) Run() error {
 		return err
 	}
 
+	// Failed updates are retried with backoff, using the latest zones
+	backoff := nfdclient.NewBackoff()
+	var retryTrigger <-chan time.Time
+
 	crTrigger := time.After(0)
 	for {
 		select {
 		case <-crTrigger:
+			if w.resourcemonitorArgs.SleepInterval > 0 {
+				crTrigger = time.After(w.resourcemonitorArgs.SleepInterval)
+			}
+
 			klog.Infof("Scanning\n")
 			podResources, err := resScan.Scan()
 			utils.KlogDump(1, "podResources are", "  ", podResources)
@@ -139,24 +147,21 @@ func (w *nfdTopologyUpdater) Run() error {
 			}
 			zones = resAggr.Aggregate(podResources)
 			utils.KlogDump(1, "After aggregating resources identified zones are", "  ", zones)
-			if err = w.Update(zones); err != nil {
-				return err
-			}
-
+			err = w.Update(zones)
 			if w.args.Oneshot {
-				return nil
+				return err
 			}
+			retryTrigger = nfdclient.ScheduleRetry(err, &backoff, "update node topology")
 
-			if w.resourcemonitorArgs.SleepInterval > 0 {
-				crTrigger = time.After(w.resourcemonitorArgs.SleepInterval)
-			}
+		case <-retryTrigger:
+			klog.Infof("retrying to update node topology")
+			retryTrigger = nfdclient.ScheduleRetry(w.Update(zones), &backoff, "update node topology")
 
 		case <-w.certWatch.Events:
+			// Update() connects on every call so just drop any stale
+			// connection, the new certificate is picked up on next update
 			klog.Infof("TLS certificate update, renewing connection to nfd-master")
 			w.Disconnect()
-			if err := w.Connect(); err != nil {
-				return err
-			}
 
 		case <-w.stop:
 			klog.Infof("shutting down nfd-topology-updater")
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/version"
+)
+
+// When adding metric names, see https://prometheus.io/docs/practices/naming/#metric-names
+const (
+	buildInfoQuery                = "nfd_worker_build_info"
+	featureDiscoveryDurationQuery = "nfd_worker_feature_discovery_duration_seconds"
+	featureDiscoveryErrorsQuery   = "nfd_worker_feature_discovery_errors_total"
+	labelSourceLabelsQuery        = "nfd_worker_label_source_labels"
+	droppedLabelsQuery            = "nfd_worker_dropped_labels_total"
+	labelingRequestsQuery         = "nfd_worker_labeling_requests_total"
+	labelingRequestDurationQuery  = "nfd_worker_labeling_request_duration_seconds"
+)
+
+// Reasons for dropping a label
+const (
+	dropReasonInvalidName  = "invalid_name"
+	dropReasonInvalidValue = "invalid_value"
+	dropReasonWhiteList    = "whitelist"
+)
+
+var (
+	buildInfo = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: buildInfoQuery,
+		Help: "Version from which Node Feature Discovery was built.",
+		ConstLabels: map[string]string{
+			"version": version.Get(),
+		},
+	})
+	featureDiscoveryDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    featureDiscoveryDurationQuery,
+		Help:    "Time taken by feature discovery of a feature source.",
+		Buckets: []float64{0.005, 0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30},
+	}, []string{"source"})
+	featureDiscoveryErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: featureDiscoveryErrorsQuery,
+		Help: "Number of failed feature discovery runs of a feature source.",
+	}, []string{"source"})
+	labelSourceLabels = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: labelSourceLabelsQuery,
+		Help: "Number of labels created by a label source in the latest discovery round.",
+	}, []string{"source"})
+	droppedLabels = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: droppedLabelsQuery,
+		Help: "Number of labels dropped because of an invalid name or value, or because of not matching the label whitelist.",
+	}, []string{"source", "reason"})
+	labelingRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: labelingRequestsQuery,
+		Help: "Number of labeling requests sent, by result.",
+	}, []string{"result"})
+	labelingRequestDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    labelingRequestDurationQuery,
+		Help:    "Time taken by labeling requests.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10},
+	})
+)
+
+func init() {
+	prometheus.MustRegister(buildInfo,
+		featureDiscoveryDuration,
+		featureDiscoveryErrors,
+		labelSourceLabels,
+		droppedLabels,
+		labelingRequests,
+		labelingRequestDuration)
+
+	buildInfo.Set(1)
+}
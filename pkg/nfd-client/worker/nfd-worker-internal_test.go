@@ -17,6 +17,7 @@ limitations under the License.
 package worker
 
 import (
+	"encoding/json"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -25,11 +26,21 @@ import (
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	. "github.com/smartystreets/goconvey/convey"
 	"github.com/stretchr/testify/mock"
 	"github.com/vektra/errors"
-
+	"golang.org/x/net/context"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakek8sclient "k8s.io/client-go/kubernetes/fake"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	fakenfdclient "sigs.k8s.io/node-feature-discovery/pkg/generated/clientset/versioned/fake"
 	"sigs.k8s.io/node-feature-discovery/pkg/labeler"
+	nfdclient "sigs.k8s.io/node-feature-discovery/pkg/nfd-client"
+	nfdmaster "sigs.k8s.io/node-feature-discovery/pkg/nfd-master"
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
 	"sigs.k8s.io/node-feature-discovery/source"
 	"sigs.k8s.io/node-feature-discovery/source/cpu"
@@ -56,6 +67,7 @@ func TestGetLabelsWithMockSources(t *testing.T) {
 			mockLabelSource.On("Name").Return(fakeLabelSourceName)
 			mockLabelSource.On("GetLabels").Return(fakeFeatures, nil)
 
+			droppedBefore := testutil.ToFloat64(droppedLabels.WithLabelValues(fakeLabelSourceName, dropReasonWhiteList))
 			returnedLabels, err := getFeatureLabels(fakeLabelSource, labelWhiteList.Regexp)
 			Convey("Proper label is returned", func() {
 				So(returnedLabels, ShouldResemble, fakeFeatureLabels)
@@ -63,6 +75,10 @@ func TestGetLabelsWithMockSources(t *testing.T) {
 			Convey("Error is nil", func() {
 				So(err, ShouldBeNil)
 			})
+			Convey("Dropped labels are counted", func() {
+				droppedAfter := testutil.ToFloat64(droppedLabels.WithLabelValues(fakeLabelSourceName, dropReasonWhiteList))
+				So(droppedAfter-droppedBefore, ShouldEqual, 1)
+			})
 		})
 
 		Convey("When I fail to get the labels from the mock source", func() {
@@ -415,3 +431,222 @@ func TestAdvertiseFeatureLabels(t *testing.T) {
 		})
 	})
 }
+
+type fakeLabelerServer struct {
+	requests []*labeler.SetLabelsRequest
+	fail     bool
+}
+
+func (s *fakeLabelerServer) SetLabels(c context.Context, r *labeler.SetLabelsRequest) (*labeler.SetLabelsReply, error) {
+	if s.fail {
+		return nil, errors.New("fake failure")
+	}
+	s.requests = append(s.requests, r)
+	return &labeler.SetLabelsReply{}, nil
+}
+
+func TestStandaloneMode(t *testing.T) {
+	Convey("When running in standalone mode", t, func() {
+		w, err := NewNfdWorker(&Args{})
+		So(err, ShouldBeNil)
+		worker := w.(*nfdWorker)
+		So(worker.configure("", `{"core": {"noPublish": false}}`), ShouldBeNil)
+
+		server := &fakeLabelerServer{}
+		worker.localLabeler = localLabelerClient{server: server}
+
+		Convey("Connect should not create a gRPC connection", func() {
+			So(worker.Connect(), ShouldBeNil)
+			So(worker.ClientConn(), ShouldBeNil)
+			So(worker.client, ShouldNotBeNil)
+
+			Convey("Labeling requests should be passed to the local labeler", func() {
+				So(worker.advertiseFeatureLabels(Labels{"feature-1": "value-1"}), ShouldBeNil)
+				So(len(server.requests), ShouldEqual, 1)
+				So(server.requests[0].Labels, ShouldResemble, map[string]string{"feature-1": "value-1"})
+			})
+		})
+	})
+}
+
+func TestStandaloneRun(t *testing.T) {
+	Convey("When running nfd-worker in standalone mode", t, func() {
+		node := &v1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        nfdclient.NodeName(),
+				Labels:      map[string]string{"kubernetes.io/os": "linux"},
+				Annotations: map[string]string{"foo": "bar"},
+			},
+		}
+		cli := fakek8sclient.NewSimpleClientset(node)
+
+		var standaloneLabeler nfdmaster.NfdLabeler
+		origNewNfdLabeler := newNfdLabeler
+		newNfdLabeler = func(args *nfdmaster.Args) (nfdmaster.NfdLabeler, error) {
+			var err error
+			standaloneLabeler, err = nfdmaster.NewNfdLabelerWithClients(args, cli, fakenfdclient.NewSimpleClientset())
+			return standaloneLabeler, err
+		}
+		defer func() { newNfdLabeler = origNewNfdLabeler }()
+
+		w, err := NewNfdWorker(&Args{
+			Oneshot:    true,
+			Standalone: true,
+			Overrides:  ConfigOverrideArgs{LabelSources: &utils.StringSliceVal{"fake"}},
+		})
+		So(err, ShouldBeNil)
+		So(w.Run(), ShouldBeNil)
+
+		Convey("The local node should be labeled directly", func() {
+			n, err := cli.CoreV1().Nodes().Get(context.TODO(), nfdclient.NodeName(), metav1.GetOptions{})
+			So(err, ShouldBeNil)
+			So(n.Labels, ShouldContainKey, "feature.node.kubernetes.io/fake-fakefeature1")
+		})
+
+		Convey("Labeling other nodes should be denied", func() {
+			_, err := standaloneLabeler.SetLabels(context.TODO(), &labeler.SetLabelsRequest{NodeName: "other-node"})
+			So(err, ShouldNotBeNil)
+		})
+	})
+}
+
+func TestPublishRetry(t *testing.T) {
+	Convey("When publishing labels", t, func() {
+		w, err := NewNfdWorker(&Args{})
+		So(err, ShouldBeNil)
+		worker := w.(*nfdWorker)
+		So(worker.configure("", `{"core": {"noPublish": false}}`), ShouldBeNil)
+
+		server := &fakeLabelerServer{fail: true}
+		worker.localLabeler = localLabelerClient{server: server}
+		worker.labels = Labels{"feature-1": "value-1"}
+		backoff := nfdclient.NewBackoff()
+
+		Convey("a failed request should drop the client and schedule a retry", func() {
+			err := worker.publish()
+			So(err, ShouldNotBeNil)
+			So(worker.client, ShouldBeNil)
+			So(nfdclient.ScheduleRetry(err, &backoff, "advertise labels"), ShouldNotBeNil)
+
+			Convey("a retry should reconnect and send the latest labels", func() {
+				server.fail = false
+				err := worker.publish()
+				So(err, ShouldBeNil)
+				So(worker.client, ShouldNotBeNil)
+				So(len(server.requests), ShouldEqual, 1)
+				So(server.requests[0].Labels, ShouldResemble, map[string]string{"feature-1": "value-1"})
+				So(nfdclient.ScheduleRetry(err, &backoff, "advertise labels"), ShouldBeNil)
+			})
+		})
+	})
+}
+
+func TestWriteOutputFile(t *testing.T) {
+	Convey("When writing the output file", t, func() {
+		tmpDir, err := ioutil.TempDir("", "*.nfd-test")
+		So(err, ShouldBeNil)
+		defer os.RemoveAll(tmpDir)
+
+		path := filepath.Join(tmpDir, "out")
+		labels := Labels{"feature-2": "value-2", "feature-1": "true"}
+		features := map[string]*feature.DomainFeatures{"fake": feature.NewDomainFeatures()}
+		features["fake"].Keys["flags"] = feature.NewKeyFeatures("flag_1")
+
+		Convey("in features.d format", func() {
+			c := outputConfig{Path: path, Format: OutputFormatFeaturesD}
+			So(writeOutputFile(c, labels, features), ShouldBeNil)
+
+			Convey("labels should be written as sorted key=value pairs", func() {
+				data, err := ioutil.ReadFile(path)
+				So(err, ShouldBeNil)
+				So(string(data), ShouldEqual, "feature-1=true\nfeature-2=value-2\n")
+			})
+		})
+		Convey("in json format", func() {
+			c := outputConfig{Path: path, Format: OutputFormatJSON}
+			So(writeOutputFile(c, labels, features), ShouldBeNil)
+
+			Convey("labels and features should be written", func() {
+				data, err := ioutil.ReadFile(path)
+				So(err, ShouldBeNil)
+				out := outputData{}
+				So(json.Unmarshal(data, &out), ShouldBeNil)
+				So(out.Labels, ShouldResemble, labels)
+				So(out.Features, ShouldResemble, features)
+			})
+		})
+		Convey("in yaml format over an existing file", func() {
+			So(ioutil.WriteFile(path, []byte("old content"), 0644), ShouldBeNil)
+			c := outputConfig{Path: path, Format: OutputFormatYAML}
+			So(writeOutputFile(c, labels, features), ShouldBeNil)
+
+			Convey("the file should be replaced", func() {
+				data, err := ioutil.ReadFile(path)
+				So(err, ShouldBeNil)
+				out := outputData{}
+				So(yaml.Unmarshal(data, &out), ShouldBeNil)
+				So(out.Labels, ShouldResemble, labels)
+
+				files, err := ioutil.ReadDir(tmpDir)
+				So(err, ShouldBeNil)
+				So(len(files), ShouldEqual, 1)
+			})
+		})
+	})
+}
+
+func TestValidateConfig(t *testing.T) {
+	Convey("When validating configuration", t, func() {
+		Convey("a valid config should produce no errors", func() {
+			data := `
+core:
+  labelWhiteList: "^foo"
+  sleepInterval: 30s
+  featureSources: [all, -fake]
+  output:
+    path: ""
+sources:
+  cpu:
+    cpuid:
+      attributeBlacklist: [AVX]
+  custom:
+    - name: "rule-1"
+      matchFeatures:
+        - feature: cpu.cpuid
+          matchExpressions:
+            AVX: {op: Exists}
+    - name: "legacy-rule"
+      matchOn:
+        - loadedKMod: ["kmod-1"]
+`
+			So(validateConfigData([]byte(data), nil), ShouldBeEmpty)
+		})
+
+		Convey("unknown options and sources should be reported", func() {
+			data := `{"core": {"sleepIntervall": "1s", "noPublsh": false, "labelSources": ["foo"]}, "sources": {"bar": {"a": 1}, "pci": {"typo": true, "foo": []}, "custom": [{"name": "r", "matchFeatures": [{"feature": "cpu.cpuid", "matchExpression": {}}]}]}}`
+			errs := validateConfigData([]byte(data), nil)
+			msgs := make([]string, len(errs))
+			for i, err := range errs {
+				msgs[i] = err.Error()
+			}
+			So(msgs, ShouldResemble, []string{
+				`unknown config option "core.noPublsh"`,
+				`unknown config option "core.sleepIntervall"`,
+				`unknown config option "sources.bar"`,
+				`unknown config option "sources.custom[0].matchFeatures[0].matchExpression"`,
+				`unknown config option "sources.pci.foo"`,
+				`unknown config option "sources.pci.typo"`,
+				`unknown source "foo" specified in core.labelSources`,
+			})
+		})
+
+		Convey("invalid custom rules should be reported", func() {
+			data := `{"sources": {"custom": [{"name": "r", "matchFeatures": [{"feature": "foo"}]}, {"matchOn": [{}]}]}}`
+			So(len(validateConfigData([]byte(data), nil)), ShouldEqual, 2)
+		})
+
+		Convey("parse errors should be reported", func() {
+			So(len(validateConfigData([]byte(`{"core": {"labelWhiteList": "("}}`), nil)), ShouldEqual, 1)
+		})
+	})
+}
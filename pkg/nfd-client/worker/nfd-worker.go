@@ -28,6 +28,7 @@ import (
 	"time"
 
 	"golang.org/x/net/context"
+	"google.golang.org/grpc"
 	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
@@ -35,13 +36,16 @@ import (
 	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
 	pb "sigs.k8s.io/node-feature-discovery/pkg/labeler"
 	nfdclient "sigs.k8s.io/node-feature-discovery/pkg/nfd-client"
+	nfdmaster "sigs.k8s.io/node-feature-discovery/pkg/nfd-master"
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
 	"sigs.k8s.io/node-feature-discovery/pkg/version"
 	"sigs.k8s.io/node-feature-discovery/source"
+	"sigs.k8s.io/node-feature-discovery/source/plugin"
 
 	// Register all source packages
 	_ "sigs.k8s.io/node-feature-discovery/source/cpu"
 	_ "sigs.k8s.io/node-feature-discovery/source/custom"
+	_ "sigs.k8s.io/node-feature-discovery/source/dmi"
 	_ "sigs.k8s.io/node-feature-discovery/source/fake"
 	_ "sigs.k8s.io/node-feature-discovery/source/iommu"
 	_ "sigs.k8s.io/node-feature-discovery/source/kernel"
@@ -49,9 +53,13 @@ import (
 	_ "sigs.k8s.io/node-feature-discovery/source/memory"
 	_ "sigs.k8s.io/node-feature-discovery/source/network"
 	_ "sigs.k8s.io/node-feature-discovery/source/pci"
+	_ "sigs.k8s.io/node-feature-discovery/source/rdma"
+	_ "sigs.k8s.io/node-feature-discovery/source/security"
 	_ "sigs.k8s.io/node-feature-discovery/source/storage"
+	_ "sigs.k8s.io/node-feature-discovery/source/sysctl"
 	_ "sigs.k8s.io/node-feature-discovery/source/system"
 	_ "sigs.k8s.io/node-feature-discovery/source/usb"
+	_ "sigs.k8s.io/node-feature-discovery/source/virt"
 )
 
 // Global config
@@ -67,7 +75,8 @@ type coreConfig struct {
 	FeatureSources []string
 	Sources        *[]string
 	LabelSources   []string
-	SleepInterval  duration
+	SleepInterval  utils.DurationVal
+	Output         outputConfig
 }
 
 type sourcesConfig map[string]source.Config
@@ -79,9 +88,21 @@ type Labels map[string]string
 type Args struct {
 	nfdclient.Args
 
-	ConfigFile string
-	Oneshot    bool
-	Options    string
+	ConfigFile         string
+	MetricsPort        int
+	Oneshot            bool
+	Options            string
+	PluginDir          string
+	ValidateConfigFile string
+	ValidateOptions    string
+
+	// Standalone mode, i.e. update the node object directly instead of
+	// sending labeling requests to nfd-master
+	Standalone     bool
+	ExtraLabelNs   utils.StringSetVal
+	Instance       string
+	Kubeconfig     string
+	ResourceLabels utils.StringSetVal
 
 	Klog      map[string]*utils.KlogFlagVal
 	Overrides ConfigOverrideArgs
@@ -104,16 +125,17 @@ type nfdWorker struct {
 	args           Args
 	certWatch      *utils.FsWatcher
 	client         pb.LabelerClient
+	localLabeler   pb.LabelerClient
 	configFilePath string
 	config         *NFDConfig
 	stop           chan struct{} // channel for signaling stop
 	featureSources []source.FeatureSource
 	labelSources   []source.LabelSource
+	labels         Labels // labels from the latest discovery round
 }
 
-type duration struct {
-	time.Duration
-}
+// newNfdLabeler creates the labeler used in standalone mode
+var newNfdLabeler = nfdmaster.NewNfdLabeler
 
 // Create new NfdWorker instance.
 func NewNfdWorker(args *Args) (nfdclient.NfdClient, error) {
@@ -141,7 +163,7 @@ func newDefaultConfig() *NFDConfig {
 	return &NFDConfig{
 		Core: coreConfig{
 			LabelWhiteList: utils.RegexpVal{Regexp: *regexp.MustCompile("")},
-			SleepInterval:  duration{60 * time.Second},
+			SleepInterval:  utils.DurationVal{Duration: 60 * time.Second},
 			FeatureSources: []string{"all"},
 			LabelSources:   []string{"all"},
 			Klog:           make(map[string]string),
@@ -154,6 +176,29 @@ func newDefaultConfig() *NFDConfig {
 func (w *nfdWorker) Run() error {
 	klog.Infof("Node Feature Discovery Worker %s", version.Get())
 	klog.Infof("NodeName: '%s'", nfdclient.NodeName())
+	if w.args.Standalone {
+		klog.Infof("running in standalone mode, nfd-master will not be contacted")
+		labeler, err := newNfdLabeler(&nfdmaster.Args{
+			ExtraLabelNs:           w.args.ExtraLabelNs,
+			FeatureRulesController: true,
+			Instance:               w.args.Instance,
+			Kubeconfig:             w.args.Kubeconfig,
+			LabelWhiteList:         utils.RegexpVal{Regexp: *regexp.MustCompile("")},
+			ResourceLabels:         w.args.ResourceLabels,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to initialize standalone mode: %v", err)
+		}
+		defer labeler.Stop()
+		w.localLabeler = localLabelerClient{server: labeler}
+	}
+
+	// Serve metrics
+	if w.args.MetricsPort > 0 {
+		m := utils.CreateMetricsServer(w.args.MetricsPort)
+		go m.Run()
+		defer m.Stop()
+	}
 
 	// Create watcher for config file and read initial configuration
 	configWatch, err := utils.CreateFsWatcher(time.Second, w.configFilePath)
@@ -170,39 +215,61 @@ func (w *nfdWorker) Run() error {
 		return err
 	}
 
-	// Connect to NFD master
-	err = w.Connect()
+	// Create watcher for the input files of feature sources. Our own output
+	// file is ignored in order not to end up in a re-discovery loop
+	sourceWatch, err := utils.CreateRecursiveFsWatcher(time.Second, getSourceWatchPaths()...)
 	if err != nil {
-		return fmt.Errorf("failed to connect: %v", err)
+		return err
 	}
+	sourceWatch.SetIgnored(w.config.Core.Output.ignorePatterns()...)
+
+	// Connection to NFD master is (re-)established lazily when publishing
+	// labels, failures are retried with backoff
 	defer w.Disconnect()
+	backoff := nfdclient.NewBackoff()
+	var retryTrigger <-chan time.Time
 
 	labelTrigger := time.After(0)
 	for {
 		select {
 		case <-labelTrigger:
+			// Pick up new source plugins
+			if w.registerPlugins() {
+				if err := w.configureCore(w.config.Core); err != nil {
+					return err
+				}
+			}
+
 			// Run feature discovery
 			for _, s := range w.featureSources {
 				klog.V(2).Infof("running discovery for %q source", s.Name())
+				start := time.Now()
 				if err := s.Discover(); err != nil {
 					klog.Errorf("feature discovery of %q source failed: %v", s.Name(), err)
+					featureDiscoveryErrors.WithLabelValues(s.Name()).Inc()
 				}
+				featureDiscoveryDuration.WithLabelValues(s.Name()).Observe(time.Since(start).Seconds())
 			}
 
 			// Get the set of feature labels.
-			labels := createFeatureLabels(w.labelSources, w.config.Core.LabelWhiteList.Regexp)
+			w.labels = createFeatureLabels(w.labelSources, w.config.Core.LabelWhiteList.Regexp)
 
-			// Update the node with the feature labels.
-			if w.client != nil {
-				err := w.advertiseFeatureLabels(labels)
-				if err != nil {
-					return fmt.Errorf("failed to advertise labels: %s", err.Error())
+			// Write labels and features into the output file
+			if w.config.Core.Output.Path != "" {
+				if err := writeOutputFile(w.config.Core.Output, w.labels, getFeatures()); err != nil {
+					klog.Errorf("failed to write output file %q: %v", w.config.Core.Output.Path, err)
 				}
 			}
 
+			// Update the node with the feature labels.
+			err := w.publish()
 			if w.args.Oneshot {
+				if err != nil {
+					return fmt.Errorf("failed to advertise labels: %v", err)
+				}
 				return nil
 			}
+			retryTrigger = nfdclient.ScheduleRetry(err, &backoff, "advertise labels")
 
 			if w.config.Core.SleepInterval.Duration > 0 {
 				labelTrigger = time.After(w.config.Core.SleepInterval.Duration)
@@ -213,29 +280,36 @@ func (w *nfdWorker) Run() error {
 			if err := w.configure(w.configFilePath, w.args.Options); err != nil {
 				return err
 			}
-			// Manage connection to master
+			sourceWatch.SetIgnored(w.config.Core.Output.ignorePatterns()...)
+			// Drop connection to master, it is re-established on the next
+			// publish, if needed
 			if w.config.Core.NoPublish {
 				w.Disconnect()
-			} else if w.ClientConn() == nil {
-				if err := w.Connect(); err != nil {
-					return err
-				}
 			}
 			// Always re-label after a re-config event. This way the new config
 			// comes into effect even if the sleep interval is long (or infinite)
 			labelTrigger = time.After(0)
 
+		case <-sourceWatch.Events:
+			klog.Infof("feature source input files changed, re-running discovery")
+			labelTrigger = time.After(0)
+
 		case <-w.certWatch.Events:
 			klog.Infof("TLS certificate update, renewing connection to nfd-master")
 			w.Disconnect()
 			if err := w.Connect(); err != nil {
-				return err
+				klog.Errorf("failed to connect to nfd-master: %v", err)
 			}
 
+		case <-retryTrigger:
+			klog.Infof("retrying to advertise labels")
+			retryTrigger = nfdclient.ScheduleRetry(w.publish(), &backoff, "advertise labels")
+
 		case <-w.stop:
 			klog.Infof("shutting down nfd-worker")
 			configWatch.Close()
 			w.certWatch.Close()
+			sourceWatch.Close()
 			return nil
 		}
 	}
@@ -249,6 +323,27 @@ func (w *nfdWorker) Stop() {
 	}
 }
 
+// publish sends the labels from the latest discovery round to nfd-master,
+// connecting first if there is no connection. The connection is dropped on
+// failure so that it gets re-established on the next attempt.
+func (w *nfdWorker) publish() error {
+	if w.config.Core.NoPublish {
+		return nil
+	}
+
+	if w.client == nil {
+		if err := w.Connect(); err != nil {
+			return fmt.Errorf("failed to connect: %v", err)
+		}
+	}
+
+	if err := w.advertiseFeatureLabels(w.labels); err != nil {
+		w.Disconnect()
+		return err
+	}
+	return nil
+}
+
 // Connect creates a client connection to the NFD master
 func (w *nfdWorker) Connect() error {
 	// Return a dummy connection in case of dry-run
@@ -256,6 +351,12 @@ func (w *nfdWorker) Connect() error {
 		return nil
 	}
 
+	// Update the node object directly in standalone mode
+	if w.localLabeler != nil {
+		w.client = w.localLabeler
+		return nil
+	}
+
 	if err := w.NfdBaseClient.Connect(); err != nil {
 		return err
 	}
@@ -270,12 +371,25 @@ func (w *nfdWorker) Disconnect() {
 	w.NfdBaseClient.Disconnect()
 	w.client = nil
 }
+
+// localLabelerClient implements the LabelerClient interface by calling a
+// LabelerServer directly, without a gRPC connection in between.
+type localLabelerClient struct {
+	server pb.LabelerServer
+}
+
+// SetLabels method of the LabelerClient interface
+func (c localLabelerClient) SetLabels(ctx context.Context, r *pb.SetLabelsRequest, _ ...grpc.CallOption) (*pb.SetLabelsReply, error) {
+	return c.server.SetLabels(ctx, r)
+}
+
 func (c *coreConfig) sanitize() {
 	if c.SleepInterval.Duration > 0 && c.SleepInterval.Duration < time.Second {
 		klog.Warningf("too short sleep-intervall specified (%s), forcing to 1s",
 			c.SleepInterval.Duration.String())
-		c.SleepInterval = duration{time.Second}
+		c.SleepInterval = utils.DurationVal{Duration: time.Second}
 	}
+	c.Output.sanitize()
 }
 
 func (w *nfdWorker) configureCore(c coreConfig) error {
@@ -390,15 +504,36 @@ func (w *nfdWorker) configureCore(c coreConfig) error {
 	return nil
 }
 
+// newDefaultSourcesConfig returns the default configuration of all
+// configurable sources
+func newDefaultSourcesConfig() sourcesConfig {
+	confSources := source.GetAllConfigurableSources()
+	c := make(sourcesConfig, len(confSources))
+	for _, s := range confSources {
+		c[s.Name()] = s.NewConfig()
+	}
+	return c
+}
+
+// registerPlugins registers feature sources for new source plugins and
+// unregisters removed ones. Returns true if the set of plugins changed.
+func (w *nfdWorker) registerPlugins() bool {
+	if w.args.PluginDir == "" {
+		return false
+	}
+	added, removed, err := plugin.Register(w.args.PluginDir)
+	if err != nil {
+		klog.Errorf("failed to register source plugins: %v", err)
+	}
+	return len(added) > 0 || len(removed) > 0
+}
+
 // Parse configuration options
 func (w *nfdWorker) configure(filepath string, overrides string) error {
 	// Create a new default config
 	c := newDefaultConfig()
 	confSources := source.GetAllConfigurableSources()
-	c.Sources = make(map[string]source.Config, len(confSources))
-	for _, s := range confSources {
-		c.Sources[s.Name()] = s.NewConfig()
-	}
+	c.Sources = newDefaultSourcesConfig()
 
 	// Try to read and parse config file
 	if filepath != "" {
@@ -436,7 +571,7 @@ func (w *nfdWorker) configure(filepath string, overrides string) error {
 		c.Core.NoPublish = *w.args.Overrides.NoPublish
 	}
 	if w.args.Overrides.SleepInterval != nil {
-		c.Core.SleepInterval = duration{*w.args.Overrides.SleepInterval}
+		c.Core.SleepInterval = utils.DurationVal{Duration: *w.args.Overrides.SleepInterval}
 	}
 	if w.args.Overrides.FeatureSources != nil {
 		c.Core.FeatureSources = *w.args.Overrides.FeatureSources
@@ -449,6 +584,8 @@ func (w *nfdWorker) configure(filepath string, overrides string) error {
 
 	w.config = c
 
+	w.registerPlugins()
+
 	if err := w.configureCore(c.Core); err != nil {
 		return err
 	}
@@ -463,6 +600,33 @@ func (w *nfdWorker) configure(filepath string, overrides string) error {
 	return nil
 }
 
+// getSourceWatchPaths returns the input files and directories of all
+// registered feature and label sources
+func getSourceWatchPaths() []string {
+	paths := make(map[string]struct{})
+	for _, s := range source.GetAllFeatureSources() {
+		if ws, ok := s.(source.WatchableSource); ok {
+			for _, p := range ws.WatchPaths() {
+				paths[p] = struct{}{}
+			}
+		}
+	}
+	for _, s := range source.GetAllLabelSources() {
+		if ws, ok := s.(source.WatchableSource); ok {
+			for _, p := range ws.WatchPaths() {
+				paths[p] = struct{}{}
+			}
+		}
+	}
+
+	ret := make([]string, 0, len(paths))
+	for p := range paths {
+		ret = append(ret, p)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
 // createFeatureLabels returns the set of feature labels from the enabled
 // sources and the whitelist argument.
 func createFeatureLabels(sources []source.LabelSource, labelWhiteList regexp.Regexp) (labels Labels) {
@@ -476,6 +640,7 @@ func createFeatureLabels(sources []source.LabelSource, labelWhiteList regexp.Reg
 			klog.Errorf("discovery failed for source %q: %v", source.Name(), err)
 			continue
 		}
+		labelSourceLabels.WithLabelValues(source.Name()).Set(float64(len(labelsFromSource)))
 
 		for name, value := range labelsFromSource {
 			labels[name] = value
@@ -523,6 +688,7 @@ func getFeatureLabels(source source.LabelSource, labelWhiteList regexp.Regexp) (
 		errs := validation.IsQualifiedName(nameForValidation)
 		if len(errs) > 0 {
 			klog.Warningf("ignoring invalid feature name '%s': %s", label, errs)
+			droppedLabels.WithLabelValues(source.Name(), dropReasonInvalidName).Inc()
 			continue
 		}
 
@@ -531,12 +697,14 @@ func getFeatureLabels(source source.LabelSource, labelWhiteList regexp.Regexp) (
 		errs = validation.IsValidLabelValue(value)
 		if len(errs) > 0 {
 			klog.Warningf("ignoring invalid feature value %s=%s: %s", label, value, errs)
+			droppedLabels.WithLabelValues(source.Name(), dropReasonInvalidValue).Inc()
 			continue
 		}
 
 		// Skip if label doesn't match labelWhiteList
 		if !labelWhiteList.MatchString(nameForWhiteListing) {
 			klog.Infof("%q does not match the whitelist (%s) and will not be published.", nameForWhiteListing, labelWhiteList.String())
+			droppedLabels.WithLabelValues(source.Name(), dropReasonWhiteList).Inc()
 			continue
 		}
 
@@ -562,42 +730,29 @@ func (w *nfdWorker) advertiseFeatureLabels(labels Labels) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	klog.Infof("sending labeling request to nfd-master")
+	if w.localLabeler != nil {
+		klog.Infof("updating node %q", nfdclient.NodeName())
+	} else {
+		klog.Infof("sending labeling request to nfd-master")
+	}
 
 	labelReq := pb.SetLabelsRequest{Labels: labels,
 		Features:   getFeatures(),
 		NfdVersion: version.Get(),
 		NodeName:   nfdclient.NodeName()}
+	start := time.Now()
 	_, err := w.client.SetLabels(ctx, &labelReq)
+	labelingRequestDuration.Observe(time.Since(start).Seconds())
 	if err != nil {
+		labelingRequests.WithLabelValues("failure").Inc()
 		klog.Errorf("failed to set node labels: %v", err)
 		return err
 	}
+	labelingRequests.WithLabelValues("success").Inc()
 
 	return nil
 }
 
-// UnmarshalJSON implements the Unmarshaler interface from "encoding/json"
-func (d *duration) UnmarshalJSON(data []byte) error {
-	var v interface{}
-	if err := json.Unmarshal(data, &v); err != nil {
-		return err
-	}
-	switch val := v.(type) {
-	case float64:
-		d.Duration = time.Duration(val)
-	case string:
-		var err error
-		d.Duration, err = time.ParseDuration(val)
-		if err != nil {
-			return err
-		}
-	default:
-		return fmt.Errorf("invalid duration %s", data)
-	}
-	return nil
-}
-
 // UnmarshalJSON implements the Unmarshaler interface from "encoding/json"
 func (c *sourcesConfig) UnmarshalJSON(data []byte) error {
 	// First do a raw parse to get the per-source data
@@ -0,0 +1,138 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+)
+
+const (
+	// OutputFormatJSON writes labels and raw features as a JSON document
+	OutputFormatJSON = "json"
+	// OutputFormatYAML writes labels and raw features as a YAML document
+	OutputFormatYAML = "yaml"
+	// OutputFormatFeaturesD writes labels as key=value lines, i.e. in the
+	// same format that the local source reads from features.d
+	OutputFormatFeaturesD = "features.d"
+)
+
+// outputConfig specifies the file where discovered labels and features are
+// written after each discovery round.
+type outputConfig struct {
+	Path   string `json:"path,omitempty"`
+	Format string `json:"format,omitempty"`
+}
+
+// outputData is the content of the output file in json and yaml formats.
+type outputData struct {
+	Labels   Labels                             `json:"labels"`
+	Features map[string]*feature.DomainFeatures `json:"features"`
+}
+
+func (c *outputConfig) sanitize() {
+	switch c.Format {
+	case OutputFormatJSON, OutputFormatYAML, OutputFormatFeaturesD:
+	case "":
+		c.Format = OutputFormatJSON
+	default:
+		klog.Warningf("invalid output format %q specified, using %q", c.Format, OutputFormatJSON)
+		c.Format = OutputFormatJSON
+	}
+}
+
+// ignorePatterns returns file patterns matching the output file and the
+// temporary files used for writing it
+func (c *outputConfig) ignorePatterns() []string {
+	if c.Path == "" {
+		return nil
+	}
+	dir, name := filepath.Split(c.Path)
+	return []string{c.Path, filepath.Join(dir, "."+name+".*")}
+}
+
+// writeOutputFile writes the labels and features into a file in the given
+// format. The file is atomically replaced by writing a temporary file first
+// and then renaming it.
+func writeOutputFile(c outputConfig, labels Labels, features map[string]*feature.DomainFeatures) error {
+	var data []byte
+	var err error
+
+	switch c.Format {
+	case OutputFormatJSON:
+		data, err = json.MarshalIndent(outputData{Labels: labels, Features: features}, "", "  ")
+	case OutputFormatYAML:
+		data, err = yaml.Marshal(outputData{Labels: labels, Features: features})
+	case OutputFormatFeaturesD:
+		data = formatFeaturesD(labels)
+	default:
+		err = fmt.Errorf("invalid output format %q", c.Format)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to format output: %w", err)
+	}
+
+	dir, name := filepath.Split(c.Path)
+	tmp, err := ioutil.TempFile(dir, "."+name+".")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary output file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := tmp.Chmod(0644); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write output file: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.Path); err != nil {
+		return fmt.Errorf("failed to replace output file: %w", err)
+	}
+
+	return nil
+}
+
+// formatFeaturesD returns labels as sorted key=value lines
+func formatFeaturesD(labels Labels) []byte {
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s=%s\n", k, labels[k])
+	}
+	return buf.Bytes()
+}
@@ -0,0 +1,266 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package worker
+
+import (
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/yaml"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/source"
+	"sigs.k8s.io/node-feature-discovery/source/custom"
+	"sigs.k8s.io/node-feature-discovery/source/plugin"
+)
+
+// ValidateConfig checks the config file specified with -validate-config and
+// the config options specified with -validate-options or -options, as well as
+// the rule files in the custom.d directory. In contrast to the normal
+// (re-)configuration of nfd-worker, unknown options, unknown source names and
+// invalid custom rules are treated as errors. All errors found are returned.
+func ValidateConfig(args *Args) []error {
+	var errs []error
+
+	// Make source plugins available for source name validation
+	if args.PluginDir != "" {
+		if _, _, err := plugin.Register(args.PluginDir); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	if args.ValidateConfigFile != "" {
+		data, err := ioutil.ReadFile(args.ValidateConfigFile)
+		if err != nil {
+			return []error{fmt.Errorf("error reading config file: %v", err)}
+		}
+		for _, err := range validateConfigData(data, args.Klog) {
+			errs = append(errs, fmt.Errorf("%s: %w", args.ValidateConfigFile, err))
+		}
+	}
+
+	if args.ValidateOptions != "" {
+		for _, err := range validateConfigData([]byte(args.ValidateOptions), args.Klog) {
+			errs = append(errs, fmt.Errorf("-validate-options: %w", err))
+		}
+	}
+
+	if args.Options != "" {
+		for _, err := range validateConfigData([]byte(args.Options), args.Klog) {
+			errs = append(errs, fmt.Errorf("-options: %w", err))
+		}
+	}
+
+	// Check the custom rule files the worker would load
+	errs = append(errs, custom.ValidateDirectory()...)
+
+	return errs
+}
+
+// validateConfigData checks one config document for errors.
+func validateConfigData(data []byte, klogOpts map[string]*utils.KlogFlagVal) []error {
+	c := newDefaultConfig()
+	c.Sources = newDefaultSourcesConfig()
+
+	if err := yaml.Unmarshal(data, c); err != nil {
+		return []error{fmt.Errorf("failed to parse config: %v", err)}
+	}
+
+	errs := []error{}
+
+	// Detect unknown options by comparing the input with the parsed config
+	var in interface{}
+	if err := yaml.Unmarshal(data, &in); err != nil {
+		return []error{fmt.Errorf("failed to parse config: %v", err)}
+	}
+	for _, key := range findUnknownKeys(in, reflect.ValueOf(c), "") {
+		errs = append(errs, fmt.Errorf("unknown config option %q", key))
+	}
+
+	// Check core config
+	for k := range c.Core.Klog {
+		if _, ok := klogOpts[k]; !ok {
+			errs = append(errs, fmt.Errorf("unknown logger option core.klog.%s", k))
+		}
+	}
+	errs = append(errs, validateSourceNames("core.featureSources", c.Core.FeatureSources,
+		func(n string) bool { return source.GetFeatureSource(n) != nil })...)
+	errs = append(errs, validateSourceNames("core.labelSources", c.Core.LabelSources,
+		func(n string) bool { return source.GetLabelSource(n) != nil })...)
+	if c.Core.Sources != nil {
+		errs = append(errs, validateSourceNames("core.sources", *c.Core.Sources,
+			func(n string) bool { return source.GetLabelSource(n) != nil })...)
+	}
+	switch c.Core.Output.Format {
+	case "", OutputFormatJSON, OutputFormatYAML, OutputFormatFeaturesD:
+	default:
+		errs = append(errs, fmt.Errorf("invalid core.output.format %q", c.Core.Output.Format))
+	}
+
+	// Check source configs
+	names := make([]string, 0, len(c.Sources))
+	for n := range c.Sources {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		if s, ok := source.GetConfigurableSource(n).(source.ValidatingSource); ok {
+			for _, err := range s.ValidateConfig(c.Sources[n]) {
+				errs = append(errs, fmt.Errorf("sources.%s: %w", n, err))
+			}
+		}
+	}
+
+	return errs
+}
+
+// validateSourceNames checks that all source names in a list are known.
+func validateSourceNames(option string, names []string, known func(string) bool) []error {
+	errs := []error{}
+	for _, name := range names {
+		if name == "all" {
+			continue
+		}
+		if !known(strings.TrimPrefix(name, "-")) {
+			errs = append(errs, fmt.Errorf("unknown source %q specified in %s", name, option))
+		}
+	}
+	return errs
+}
+
+// findUnknownKeys returns the (dot-separated) paths of all map keys in the
+// input data that do not correspond to a struct field or a map entry in the
+// parsed data. Struct fields are matched case-insensitively against their json
+// names, similar to "encoding/json".
+func findUnknownKeys(in interface{}, out reflect.Value, path string) []string {
+	unknown := []string{}
+
+	// Dereference pointers and interfaces, using the zero value for nil
+	// pointers so that struct fields can still be resolved
+	for out.Kind() == reflect.Ptr || out.Kind() == reflect.Interface {
+		if out.IsNil() {
+			if out.Kind() == reflect.Interface {
+				return unknown
+			}
+			out = reflect.Zero(out.Type().Elem())
+		} else {
+			out = out.Elem()
+		}
+	}
+
+	switch inVal := in.(type) {
+	case map[string]interface{}:
+		var lookup func(string) (reflect.Value, bool)
+		switch out.Kind() {
+		case reflect.Struct:
+			fields := jsonFields(out)
+			if m, ok := fields[""]; ok {
+				// Struct inlining a map
+				return findUnknownKeys(in, m, path)
+			}
+			lookup = func(k string) (reflect.Value, bool) {
+				v, ok := fields[strings.ToLower(k)]
+				return v, ok
+			}
+		case reflect.Map:
+			if out.Type().Key().Kind() != reflect.String {
+				return unknown
+			}
+			lookup = func(k string) (reflect.Value, bool) {
+				v := out.MapIndex(reflect.ValueOf(k).Convert(out.Type().Key()))
+				return v, v.IsValid()
+			}
+		default:
+			return unknown
+		}
+
+		keys := make([]string, 0, len(inVal))
+		for k := range inVal {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			keyPath := k
+			if path != "" {
+				keyPath = path + "." + k
+			}
+			if v, ok := lookup(k); ok {
+				unknown = append(unknown, findUnknownKeys(inVal[k], v, keyPath)...)
+			} else {
+				unknown = append(unknown, keyPath)
+			}
+		}
+	case []interface{}:
+		if out.Kind() != reflect.Slice || out.Len() != len(inVal) {
+			return unknown
+		}
+		for i := range inVal {
+			unknown = append(unknown, findUnknownKeys(inVal[i], out.Index(i), fmt.Sprintf("%s[%d]", path, i))...)
+		}
+	}
+
+	return unknown
+}
+
+// jsonFields returns the fields of a struct value, indexed by their
+// lower-cased json name. Fields of embedded structs are promoted, similar to
+// "encoding/json". An inlined map is returned with an empty name.
+func jsonFields(v reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+		fv := v.Field(i)
+		if f.Anonymous && name == "" {
+			for fv.Kind() == reflect.Ptr {
+				if fv.IsNil() {
+					fv = reflect.Zero(fv.Type().Elem())
+				} else {
+					fv = fv.Elem()
+				}
+			}
+			switch fv.Kind() {
+			case reflect.Struct:
+				for n, sub := range jsonFields(fv) {
+					if _, ok := fields[n]; !ok {
+						fields[n] = sub
+					}
+				}
+				continue
+			case reflect.Map:
+				fields[""] = fv
+				continue
+			}
+		}
+		if f.PkgPath != "" {
+			// Unexported field
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		fields[strings.ToLower(name)] = fv
+	}
+	return fields
+}
@@ -40,13 +40,16 @@ import (
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/labels"
+	k8sclient "k8s.io/client-go/kubernetes"
 	restclient "k8s.io/client-go/rest"
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
 	"sigs.k8s.io/node-feature-discovery/pkg/apihelper"
 	nfdv1alpha1 "sigs.k8s.io/node-feature-discovery/pkg/apis/nfd/v1alpha1"
+	nfdclientset "sigs.k8s.io/node-feature-discovery/pkg/generated/clientset/versioned"
 	pb "sigs.k8s.io/node-feature-discovery/pkg/labeler"
+	nfdclient "sigs.k8s.io/node-feature-discovery/pkg/nfd-client"
 	topologypb "sigs.k8s.io/node-feature-discovery/pkg/topologyupdater"
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
 	"sigs.k8s.io/node-feature-discovery/pkg/version"
@@ -122,6 +125,22 @@ type nfdMaster struct {
 
 // Create new NfdMaster server instance.
 func NewNfdMaster(args *Args) (NfdMaster, error) {
+	nfd, err := newNfdMaster(args)
+	if err != nil {
+		return nfd, err
+	}
+
+	// Initialize Kubernetes API helpers
+	if !args.NoPublish {
+		if err := nfd.initAPIHelper(nil); err != nil {
+			return nfd, err
+		}
+	}
+
+	return nfd, nil
+}
+
+func newNfdMaster(args *Args) (*nfdMaster, error) {
 	nfd := &nfdMaster{args: *args,
 		nodeName: os.Getenv("NODE_NAME"),
 		ready:    make(chan bool, 1),
@@ -152,16 +171,63 @@ func NewNfdMaster(args *Args) (NfdMaster, error) {
 		}
 	}
 
-	// Initialize Kubernetes API helpers
+	return nfd, nil
+}
+
+// NfdLabeler is a labeler that applies labeling requests directly to the node
+// object in the cluster, without running a gRPC server.
+type NfdLabeler interface {
+	pb.LabelerServer
+	Stop()
+}
+
+// nfdLabeler restricts an nfdMaster to labeling the local node only
+type nfdLabeler struct {
+	*nfdMaster
+}
+
+// NewNfdLabeler creates a new labeler that applies labeling requests of the
+// local node directly to its node object. It applies the same label
+// filtering, NodeFeatureRule processing and extended resource handling as
+// nfd-master. Used by nfd-worker in standalone mode.
+func NewNfdLabeler(args *Args) (NfdLabeler, error) {
+	return NewNfdLabelerWithClients(args, nil, nil)
+}
+
+// NewNfdLabelerWithClients creates a new labeler, like NewNfdLabeler, that
+// uses the given clients for accessing the node objects and NodeFeatureRules.
+// Clients that are nil are created from the kubeconfig.
+func NewNfdLabelerWithClients(args *Args, cli k8sclient.Interface, nfdCli nfdclientset.Interface) (NfdLabeler, error) {
+	m, err := newNfdMaster(args)
+	if err != nil {
+		return nil, err
+	}
+
 	if !args.NoPublish {
-		kubeconfig, err := nfd.getKubeconfig()
-		if err != nil {
-			return nfd, err
+		if err := m.initAPIHelper(cli); err != nil {
+			return nil, err
 		}
-		nfd.apihelper = apihelper.K8sHelpers{Kubeconfig: kubeconfig}
 	}
+	if err := m.startNfdController(nfdCli); err != nil {
+		return nil, err
+	}
+	return nfdLabeler{nfdMaster: m}, nil
+}
 
-	return nfd, nil
+// SetLabels method of the LabelerServer interface. Only requests for the
+// local node are accepted.
+func (l nfdLabeler) SetLabels(c context.Context, r *pb.SetLabelsRequest) (*pb.SetLabelsReply, error) {
+	if r.NodeName != nfdclient.NodeName() {
+		return &pb.SetLabelsReply{}, fmt.Errorf("labeling of node %q denied, only the local node %q may be labeled", r.NodeName, nfdclient.NodeName())
+	}
+	return l.nfdMaster.SetLabels(c, r)
+}
+
+// Stop the labeler
+func (l nfdLabeler) Stop() {
+	if l.nfdController != nil {
+		l.nfdController.stop()
+	}
 }
 
 // Run NfdMaster server. The method returns in case of fatal errors or if Stop()
@@ -177,13 +243,8 @@ func (m *nfdMaster) Run() error {
 		return m.prune()
 	}
 
-	if m.args.FeatureRulesController {
-		kubeconfig, err := m.getKubeconfig()
-		if err != nil {
-			return err
-		}
-		klog.Info("starting nfd LabelRule controller")
-		m.nfdController = newNfdController(kubeconfig)
+	if err := m.startNfdController(nil); err != nil {
+		return err
 	}
 
 	if !m.args.NoPublish {
@@ -601,6 +662,42 @@ func (m *nfdMaster) annotationName(name string) string {
 	return path.Join(m.annotationNs, name)
 }
 
+// initAPIHelper initializes the Kubernetes API helpers. The client is created
+// from the kubeconfig if nil.
+func (m *nfdMaster) initAPIHelper(cli k8sclient.Interface) error {
+	if cli != nil {
+		m.apihelper = apihelper.K8sHelpers{Client: cli}
+		return nil
+	}
+	kubeconfig, err := m.getKubeconfig()
+	if err != nil {
+		return err
+	}
+	m.apihelper = apihelper.K8sHelpers{Kubeconfig: kubeconfig}
+	return nil
+}
+
+// startNfdController starts the NodeFeatureRule controller, if enabled. The
+// client is created from the kubeconfig if nil.
+func (m *nfdMaster) startNfdController(nfdCli nfdclientset.Interface) error {
+	if !m.args.FeatureRulesController {
+		return nil
+	}
+	if nfdCli == nil {
+		kubeconfig, err := m.getKubeconfig()
+		if err != nil {
+			return err
+		}
+		nfdCli, err = nfdclientset.NewForConfig(kubeconfig)
+		if err != nil {
+			return err
+		}
+	}
+	klog.Info("starting nfd LabelRule controller")
+	m.nfdController = newNfdController(nfdCli)
+	return nil
+}
+
 func (m *nfdMaster) getKubeconfig() (*restclient.Config, error) {
 	var err error
 	if m.kubeconfig == nil {
@@ -20,7 +20,6 @@ import (
 	"time"
 
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
-	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/klog/v2"
 
@@ -37,13 +36,11 @@ type nfdController struct {
 	stopChan chan struct{}
 }
 
-func newNfdController(config *restclient.Config) *nfdController {
+func newNfdController(nfdClient nfdclientset.Interface) *nfdController {
 	c := &nfdController{
 		stopChan: make(chan struct{}, 1),
 	}
 
-	nfdClient := nfdclientset.NewForConfigOrDie(config)
-
 	informerFactory := nfdinformers.NewSharedInformerFactory(nfdClient, 5*time.Minute)
 	informer := informerFactory.Nfd().V1alpha1().NodeFeatureRules()
 	informer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
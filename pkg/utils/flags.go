@@ -23,6 +23,7 @@ import (
 	"regexp"
 	"sort"
 	"strings"
+	"time"
 )
 
 // RegexpVal is a wrapper for regexp command line flags
@@ -58,6 +59,39 @@ func (a *RegexpVal) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// DurationVal is a wrapper for time.Duration config options
+type DurationVal struct {
+	time.Duration
+}
+
+// UnmarshalJSON implements the Unmarshaler interface from "encoding/json".
+// Both duration strings (e.g. "1m30s") and plain numbers (nanoseconds) are
+// accepted.
+func (a *DurationVal) UnmarshalJSON(data []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	switch val := v.(type) {
+	case float64:
+		a.Duration = time.Duration(val)
+	case string:
+		d, err := time.ParseDuration(val)
+		if err != nil {
+			return err
+		}
+		a.Duration = d
+	default:
+		return fmt.Errorf("invalid duration %s", data)
+	}
+	return nil
+}
+
+// MarshalJSON implements the Marshaler interface from "encoding/json"
+func (a DurationVal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(a.Duration.String())
+}
+
 // StringSetVal is a Value encapsulating a set of comma-separated strings
 type StringSetVal map[string]struct{}
 
@@ -18,7 +18,9 @@ package utils
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/fsnotify/fsnotify"
@@ -32,14 +34,32 @@ type FsWatcher struct {
 	Events    chan struct{}
 	ratelimit time.Duration
 	names     []string
+	recursive bool
 	paths     map[string]struct{}
+	dirs      map[string]struct{}
+
+	// mutex protects the fields below, and swapping of the fsnotify watcher
+	mutex   sync.Mutex
+	closed  bool
+	ignored []string
 }
 
 // CreateFsWatcher creates a new FsWatcher
 func CreateFsWatcher(ratelimit time.Duration, names ...string) (*FsWatcher, error) {
+	return createFsWatcher(ratelimit, false, names...)
+}
+
+// CreateRecursiveFsWatcher creates a new FsWatcher that watches directories
+// recursively, i.e. changes in any file under them generate an event.
+func CreateRecursiveFsWatcher(ratelimit time.Duration, names ...string) (*FsWatcher, error) {
+	return createFsWatcher(ratelimit, true, names...)
+}
+
+func createFsWatcher(ratelimit time.Duration, recursive bool, names ...string) (*FsWatcher, error) {
 	w := &FsWatcher{
 		Events:    make(chan struct{}),
 		names:     names,
+		recursive: recursive,
 		ratelimit: ratelimit,
 	}
 
@@ -52,8 +72,26 @@ func CreateFsWatcher(ratelimit time.Duration, names ...string) (*FsWatcher, erro
 	return w, nil
 }
 
+// Close stops watching
+func (w *FsWatcher) Close() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.closed = true
+	if w.Watcher == nil {
+		return nil
+	}
+	return w.Watcher.Close()
+}
+
 // reset resets the file watches
 func (w *FsWatcher) reset(names ...string) error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	if w.closed {
+		return nil
+	}
 	if err := w.initWatcher(); err != nil {
 		return err
 	}
@@ -71,6 +109,7 @@ func (w *FsWatcher) initWatcher() error {
 		}
 	}
 	w.paths = make(map[string]struct{})
+	w.dirs = make(map[string]struct{})
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
@@ -87,6 +126,7 @@ func (w *FsWatcher) add(names ...string) error {
 		if name == "" {
 			continue
 		}
+		name = filepath.Clean(name)
 
 		added := false
 		// Add watches for all directory components so that we catch e.g. renames
@@ -112,11 +152,78 @@ func (w *FsWatcher) add(names ...string) error {
 			// Want to be sure that we watch something
 			return fmt.Errorf("failed to add any watch")
 		}
+
+		// Watch the contents of directories, recursively
+		if !w.recursive {
+			continue
+		}
+		if fi, err := os.Stat(name); err == nil && fi.IsDir() {
+			w.addDir(name)
+		}
 	}
 
 	return nil
 }
 
+func (w *FsWatcher) addDir(dir string) {
+	err := filepath.Walk(dir, func(p string, info os.FileInfo, err error) error {
+		if err != nil {
+			klog.V(1).Infof("failed to walk %q: %v", p, err)
+			return nil
+		}
+		if !info.IsDir() {
+			return nil
+		}
+		p = filepath.Clean(p)
+		if _, ok := w.paths[p]; !ok {
+			if err := w.Add(p); err != nil {
+				klog.V(1).Infof("failed to add fsnotify watch for %q: %v", p, err)
+			} else {
+				klog.V(1).Infof("added fsnotify watch %q", p)
+			}
+			w.paths[p] = struct{}{}
+		}
+		w.dirs[p] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		klog.V(1).Infof("failed to walk %q: %v", dir, err)
+	}
+}
+
+// SetIgnored sets patterns (in the syntax of filepath.Match) of files whose
+// changes do not generate events, e.g. files written by the user of the
+// watcher itself.
+func (w *FsWatcher) SetIgnored(patterns ...string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	w.ignored = patterns
+}
+
+// isIgnored returns true if the given path matches any ignored pattern
+func (w *FsWatcher) isIgnored(name string) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, pattern := range w.ignored {
+		if ok, _ := filepath.Match(filepath.Clean(pattern), name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// isWatched returns true if changes of the given path are of interest
+func (w *FsWatcher) isWatched(name string) bool {
+	if w.isIgnored(name) {
+		return false
+	}
+	if _, ok := w.paths[name]; ok {
+		return true
+	}
+	_, ok := w.dirs[filepath.Dir(name)]
+	return ok
+}
+
 func (w *FsWatcher) watch() {
 	var ratelimiter <-chan time.Time
 	for {
@@ -130,7 +237,7 @@ func (w *FsWatcher) watch() {
 
 			// If any of our paths change
 			name := filepath.Clean(e.Name)
-			if _, ok := w.paths[filepath.Clean(name)]; ok {
+			if w.isWatched(name) {
 				klog.V(2).Infof("fsnotify %s event in %q detected", e, name)
 
 				// Rate limiter. In certain filesystem operations we get
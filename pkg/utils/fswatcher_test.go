@@ -0,0 +1,133 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFsWatcherRecursive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fswatcher-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	subDir := filepath.Join(dir, "sub")
+	if err := os.Mkdir(subDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := CreateRecursiveFsWatcher(10*time.Millisecond, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	expectEvent := func(desc string) {
+		select {
+		case <-w.Events:
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no event received after %s", desc)
+		}
+	}
+
+	// File in the top-level directory
+	if err := ioutil.WriteFile(filepath.Join(dir, "foo"), []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent("creating a file")
+
+	// File in a subdirectory
+	if err := ioutil.WriteFile(filepath.Join(subDir, "bar"), []byte("bar"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent("creating a file in a subdirectory")
+
+	// File in a subdirectory created after the watcher
+	newDir := filepath.Join(subDir, "new")
+	if err := os.Mkdir(newDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent("creating a subdirectory")
+	if err := ioutil.WriteFile(filepath.Join(newDir, "baz"), []byte("baz"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent("creating a file in a new subdirectory")
+
+	// File removal
+	if err := os.Remove(filepath.Join(subDir, "bar")); err != nil {
+		t.Fatal(err)
+	}
+	expectEvent("removing a file")
+
+	// Changes of ignored files
+	w.SetIgnored(filepath.Join(dir, "out"), filepath.Join(dir, ".out.*"))
+	if err := ioutil.WriteFile(filepath.Join(dir, ".out.123"), []byte("out"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(filepath.Join(dir, ".out.123"), filepath.Join(dir, "out")); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Events:
+		t.Fatalf("unexpected event after writing an ignored file")
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestFsWatcherNonRecursive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fswatcher-test-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "foo")
+	if err := ioutil.WriteFile(file, []byte("foo"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	w, err := CreateFsWatcher(10*time.Millisecond, file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+
+	// Other files in the same directory are not watched
+	if err := ioutil.WriteFile(filepath.Join(dir, "bar"), []byte("bar"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Events:
+		t.Fatalf("unexpected event after writing an unwatched file")
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	if err := ioutil.WriteFile(file, []byte("foo2"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-w.Events:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("no event received after changing the watched file")
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSanitizeLabelValue(t *testing.T) {
+	assert.Equal(t, "Dell_Inc", SanitizeLabelValue("Dell Inc."))
+	assert.Equal(t, "PowerEdge_R740", SanitizeLabelValue("PowerEdge R740"))
+	assert.Equal(t, "Intel_R_Xeon_R_Processor", SanitizeLabelValue("Intel(R) Xeon(R) Processor"))
+	assert.Equal(t, "2.10.2", SanitizeLabelValue("2.10.2"))
+	assert.Equal(t, "", SanitizeLabelValue(" ( ) "))
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"k8s.io/klog/v2"
+)
+
+// MetricsServer is a HTTP server exposing Prometheus metrics from the
+// default registry
+type MetricsServer struct {
+	srv *http.Server
+}
+
+// CreateMetricsServer creates a new MetricsServer listening on the given port
+func CreateMetricsServer(port int) *MetricsServer {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+
+	return &MetricsServer{
+		srv: &http.Server{
+			Addr:    fmt.Sprintf(":%d", port),
+			Handler: mux,
+		},
+	}
+}
+
+// Run starts serving metrics. It blocks until the server is stopped.
+func (s *MetricsServer) Run() {
+	klog.Infof("metrics server starting on %s", s.srv.Addr)
+	if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		klog.Errorf("metrics server failed: %v", err)
+	}
+}
+
+// Stop stops the metrics server
+func (s *MetricsServer) Stop() {
+	if err := s.srv.Close(); err != nil {
+		klog.Errorf("failed to stop metrics server: %v", err)
+	}
+}
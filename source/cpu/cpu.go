@@ -17,9 +17,6 @@ limitations under the License.
 package cpu
 
 import (
-	"io/ioutil"
-	"strconv"
-
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
@@ -30,13 +27,22 @@ import (
 const Name = "cpu"
 
 const (
-	CpuidFeature    = "cpuid"
-	CstateFeature   = "cstate"
-	PstateFeature   = "pstate"
-	RdtFeature      = "rdt"
-	SgxFeature      = "sgx"
-	SstFeature      = "sst"
-	TopologyFeature = "topology"
+	CacheFeature         = "cache"
+	CpufreqFeature       = "cpufreq"
+	CpufreqPolicyFeature = "cpufreq_policy"
+	CpuidFeature         = "cpuid"
+	CpuidleFeature       = "cpuidle"
+	CpuidleStateFeature  = "cpuidle_state"
+	CstateFeature        = "cstate"
+	ModelFeature         = "model"
+	NumaFeature          = "numa"
+	PstateFeature        = "pstate"
+	RdtFeature           = "rdt"
+	RdtCapacityFeature   = "rdt_capacity"
+	SgxFeature           = "sgx"
+	SstFeature           = "sst"
+	TopologyFeature      = "topology"
+	X86_64Feature        = "x86_64"
 )
 
 // Configuration file options
@@ -45,14 +51,24 @@ type cpuidConfig struct {
 	AttributeWhitelist []string `json:"attributeWhitelist,omitempty"`
 }
 
+type modelConfig struct {
+	LabelFields []string `json:"labelFields,omitempty"`
+}
+
+type topologyConfig struct {
+	LabelFields []string `json:"labelFields,omitempty"`
+}
+
 type Config struct {
-	Cpuid cpuidConfig `json:"cpuid,omitempty"`
+	Cpuid    cpuidConfig    `json:"cpuid,omitempty"`
+	Model    modelConfig    `json:"model,omitempty"`
+	Topology topologyConfig `json:"topology,omitempty"`
 }
 
 // newDefaultConfig returns a new config with pre-populated defaults
 func newDefaultConfig() *Config {
 	return &Config{
-		cpuidConfig{
+		Cpuid: cpuidConfig{
 			AttributeBlacklist: []string{
 				"BMI1",
 				"BMI2",
@@ -81,6 +97,12 @@ func newDefaultConfig() *Config {
 			},
 			AttributeWhitelist: []string{},
 		},
+		Model: modelConfig{
+			LabelFields: []string{},
+		},
+		Topology: topologyConfig{
+			LabelFields: []string{},
+		},
 	}
 }
 
@@ -144,6 +166,15 @@ func (s *cpuSource) GetLabels() (source.FeatureLabels, error) {
 		labels["cstate."+k] = v
 	}
 
+	// Model
+	for _, f := range s.config.Model.LabelFields {
+		if v, ok := features.Values[ModelFeature].Elements[f]; ok {
+			if v = utils.SanitizeLabelValue(v); v != "" {
+				labels["model."+f] = v
+			}
+		}
+	}
+
 	// Pstate
 	for k, v := range features.Values[PstateFeature].Elements {
 		labels["pstate."+k] = v
@@ -164,11 +195,23 @@ func (s *cpuSource) GetLabels() (source.FeatureLabels, error) {
 		labels["power.sst_"+k] = v
 	}
 
+	// x86-64 microarchitecture level, named as in the psABI, e.g. "v3"
+	if v, ok := features.Values[X86_64Feature].Elements["level"]; ok {
+		labels["x86_64.level"] = "v" + v
+	}
+
 	// Hyperthreading
 	if v, ok := features.Values[TopologyFeature].Elements["hardware_multithreading"]; ok {
 		labels["hardware_multithreading"] = v
 	}
 
+	// Topology
+	for _, f := range s.config.Topology.LabelFields {
+		if v, ok := features.Values[TopologyFeature].Elements[f]; ok {
+			labels["topology."+f] = v
+		}
+	}
+
 	return labels, nil
 }
 
@@ -177,7 +220,14 @@ func (s *cpuSource) Discover() error {
 	s.features = feature.NewDomainFeatures()
 
 	// Detect CPUID
-	s.features.Keys[CpuidFeature] = feature.NewKeyFeatures(getCpuidFlags()...)
+	cpuidFlags := getCpuidFlags()
+	s.features.Keys[CpuidFeature] = feature.NewKeyFeatures(cpuidFlags...)
+
+	// Detect x86-64 microarchitecture level
+	s.features.Values[X86_64Feature] = feature.NewValueFeatures(discoverX86_64Level(cpuidFlags))
+
+	// Detect cpu model
+	s.features.Values[ModelFeature] = feature.NewValueFeatures(getCpuModel())
 
 	// Detect cstate configuration
 	cstate, err := detectCstate()
@@ -194,8 +244,27 @@ func (s *cpuSource) Discover() error {
 	}
 	s.features.Values[PstateFeature] = feature.NewValueFeatures(pstate)
 
+	// Detect cpu frequency scaling features
+	if cpufreq, policies, err := discoverCpufreq(); err != nil {
+		klog.Errorf("failed to detect cpufreq features: %v", err)
+	} else {
+		s.features.Values[CpufreqFeature] = feature.NewValueFeatures(cpufreq)
+		s.features.Instances[CpufreqPolicyFeature] = feature.NewInstanceFeatures(policies)
+	}
+
+	// Detect cpu idle state features
+	if cpuidle, states, err := discoverCpuidle(); err != nil {
+		klog.Errorf("failed to detect cpuidle features: %v", err)
+	} else {
+		s.features.Values[CpuidleFeature] = feature.NewValueFeatures(cpuidle)
+		s.features.Instances[CpuidleStateFeature] = feature.NewInstanceFeatures(states)
+	}
+
 	// Detect RDT features
 	s.features.Keys[RdtFeature] = feature.NewKeyFeatures(discoverRDT()...)
+	rdtCapacity := discoverRDTCapacity()
+	discoverResctrl(rdtCapacity)
+	s.features.Values[RdtCapacityFeature] = feature.NewValueFeatures(rdtCapacity)
 
 	// Detect SGX features
 	s.features.Values[SgxFeature] = feature.NewValueFeatures(discoverSGX())
@@ -203,9 +272,23 @@ func (s *cpuSource) Discover() error {
 	// Detect SST features
 	s.features.Values[SstFeature] = feature.NewValueFeatures(discoverSST())
 
-	// Detect hyper-threading
+	// Detect hyper-threading and cpu topology
 	s.features.Values[TopologyFeature] = feature.NewValueFeatures(discoverTopology())
 
+	// Detect cpus of NUMA nodes
+	if numa, err := discoverNuma(); err != nil {
+		klog.Errorf("failed to detect numa nodes: %v", err)
+	} else {
+		s.features.Instances[NumaFeature] = feature.NewInstanceFeatures(numa)
+	}
+
+	// Detect cpu caches
+	if caches, err := discoverCaches(); err != nil {
+		klog.Errorf("failed to detect cpu caches: %v", err)
+	} else {
+		s.features.Instances[CacheFeature] = feature.NewInstanceFeatures(caches)
+	}
+
 	utils.KlogDump(3, "discovered cpu features:", "  ", s.features)
 
 	return nil
@@ -219,43 +302,6 @@ func (s *cpuSource) GetFeatures() *feature.DomainFeatures {
 	return s.features
 }
 
-func discoverTopology() map[string]string {
-	features := make(map[string]string)
-
-	if ht, err := haveThreadSiblings(); err != nil {
-		klog.Errorf("failed to detect hyper-threading: %v", err)
-	} else {
-		features["hardware_multithreading"] = strconv.FormatBool(ht)
-	}
-
-	return features
-}
-
-// Check if any (online) CPUs have thread siblings
-func haveThreadSiblings() (bool, error) {
-
-	files, err := ioutil.ReadDir(source.SysfsDir.Path("bus/cpu/devices"))
-	if err != nil {
-		return false, err
-	}
-
-	for _, file := range files {
-		// Try to read siblings from topology
-		siblings, err := ioutil.ReadFile(source.SysfsDir.Path("bus/cpu/devices", file.Name(), "topology/thread_siblings_list"))
-		if err != nil {
-			return false, err
-		}
-		for _, char := range siblings {
-			// If list separator found, we determine that there are multiple siblings
-			if char == ',' || char == '-' {
-				return true, nil
-			}
-		}
-	}
-	// No siblings were found
-	return false, nil
-}
-
 func (s *cpuSource) initCpuidFilter() {
 	newFilter := keyFilter{keys: map[string]struct{}{}}
 	if len(s.config.Cpuid.AttributeWhitelist) > 0 {
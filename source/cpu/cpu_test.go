@@ -17,9 +17,12 @@ limitations under the License.
 package cpu
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/source/testutils"
 )
 
 func TestCpuSource(t *testing.T) {
@@ -33,3 +36,217 @@ func TestCpuSource(t *testing.T) {
 	assert.Empty(t, l)
 
 }
+
+func TestTopology(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+
+	// One socket with two cores and two threads per core
+	files := map[string]string{
+		"bus/node/devices/node0/cpulist": "0-3",
+	}
+	for cpu := 0; cpu < 4; cpu++ {
+		dir := fmt.Sprintf("bus/cpu/devices/cpu%d/", cpu)
+		core := cpu % 2
+		siblings := fmt.Sprintf("%d,%d", core, core+2)
+		files[dir+"topology/physical_package_id"] = "0"
+		files[dir+"topology/core_id"] = fmt.Sprint(core)
+		files[dir+"topology/thread_siblings_list"] = siblings
+		files[dir+"cache/index0/level"] = "1"
+		files[dir+"cache/index0/type"] = "Data"
+		files[dir+"cache/index0/size"] = "48K"
+		files[dir+"cache/index0/shared_cpu_list"] = siblings
+		files[dir+"cache/index3/level"] = "3"
+		files[dir+"cache/index3/type"] = "Unified"
+		files[dir+"cache/index3/size"] = "30720K"
+		files[dir+"cache/index3/shared_cpu_list"] = "0-3"
+	}
+	// Caches with missing attributes are skipped
+	files["bus/cpu/devices/cpu0/cache/index2/level"] = "2"
+	testutils.WriteFiles(t, sysfs, files)
+
+	expected := map[string]string{
+		"hardware_multithreading": "true",
+		"cpu_count":               "4",
+		"socket_count":            "1",
+		"die_count":               "1",
+		"dies_per_socket":         "1",
+		"cores_per_socket":        "2",
+		"threads_per_core":        "2",
+		"uniform_topology":        "true",
+		"l1d_cache_size":          "49152",
+		"l3_cache_size":           "31457280",
+	}
+	assert.Equal(t, expected, discoverTopology())
+
+	numa, err := discoverNuma()
+	assert.Nil(t, err, err)
+	assert.Len(t, numa, 1)
+	assert.Equal(t, map[string]string{"node": "0", "cpus": "0-3", "cpu_count": "4"}, numa[0].Attributes)
+
+	caches, err := discoverCaches()
+	assert.Nil(t, err, err)
+	// Two L1d caches (one per core) and one shared L3
+	assert.Len(t, caches, 3)
+	assert.Equal(t, "4", caches[1].Attributes["shared_cpu_count"])
+
+	// Add a single-threaded core on another die, e.g. a hybrid system
+	testutils.WriteFiles(t, sysfs, map[string]string{
+		"bus/cpu/devices/cpu4/topology/physical_package_id":  "0",
+		"bus/cpu/devices/cpu4/topology/die_id":               "1",
+		"bus/cpu/devices/cpu4/topology/core_id":              "0",
+		"bus/cpu/devices/cpu4/topology/thread_siblings_list": "4",
+	})
+	topology := make(map[string]string)
+	assert.Nil(t, discoverCoreCounts(topology))
+	assert.Equal(t, map[string]string{
+		"cpu_count":        "5",
+		"socket_count":     "1",
+		"die_count":        "2",
+		"dies_per_socket":  "2",
+		"cores_per_socket": "3",
+		"threads_per_core": "2",
+		"uniform_topology": "false",
+	}, topology)
+}
+
+func TestCpuListCount(t *testing.T) {
+	for list, expected := range map[string]int{"": 0, "0": 1, "0-3": 4, "0-3,8,10-11": 7} {
+		n, err := cpuListCount(list)
+		assert.Nil(t, err, err)
+		assert.Equal(t, expected, n, list)
+	}
+	_, err := cpuListCount("3-1")
+	assert.NotNil(t, err)
+}
+
+func TestParseCpuinfo(t *testing.T) {
+	cpuinfo := `processor	: 0
+vendor_id	: GenuineIntel
+cpu family	: 6
+model		: 143
+model name	: Intel(R) Xeon(R) Processor
+stepping	: 8
+microcode	: 0x2b000111
+
+processor	: 1
+vendor_id	: GenuineIntel
+model		: 143
+`
+	info := parseCpuinfo([]byte(cpuinfo))
+	assert.Equal(t, "0", info["processor"])
+	assert.Equal(t, "Intel(R) Xeon(R) Processor", info["model name"])
+	assert.Equal(t, "0x2b000111", info["microcode"])
+	assert.Equal(t, "8", info["stepping"])
+}
+
+func TestPowerManagement(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+
+	files := map[string]string{
+		"devices/system/cpu/cpufreq/boost":                    "1",
+		"devices/system/cpu/cpufreq/policy0/affected_cpus":    "0 1",
+		"devices/system/cpu/cpufreq/policy0/scaling_driver":   "acpi-cpufreq",
+		"devices/system/cpu/cpufreq/policy0/scaling_governor": "performance",
+		"devices/system/cpu/cpufreq/policy0/cpuinfo_max_freq": "3500000",
+		"devices/system/cpu/cpufreq/policy2/affected_cpus":    "2 3",
+		"devices/system/cpu/cpufreq/policy2/scaling_driver":   "acpi-cpufreq",
+		"devices/system/cpu/cpufreq/policy2/scaling_governor": "schedutil",
+		"devices/system/cpu/cpufreq/policy4/affected_cpus":    "",
+		"devices/system/cpu/cpuidle/current_driver":           "acpi_idle",
+		"devices/system/cpu/cpuidle/current_governor_ro":      "menu",
+		"devices/system/cpu/cpu0/cpuidle/state0/name":         "POLL",
+		"devices/system/cpu/cpu0/cpuidle/state0/latency":      "0",
+		"devices/system/cpu/cpu0/cpuidle/state0/disable":      "0",
+		"devices/system/cpu/cpu0/cpuidle/state10/name":        "C3",
+		"devices/system/cpu/cpu0/cpuidle/state10/latency":     "100",
+		"devices/system/cpu/cpu0/cpuidle/state10/disable":     "1",
+		"devices/system/cpu/cpu0/cpuidle/state2/name":         "C1",
+		"devices/system/cpu/cpu0/cpuidle/state2/latency":      "1",
+		"devices/system/cpu/cpu0/cpuidle/state2/disable":      "0",
+	}
+	testutils.WriteFiles(t, sysfs, files)
+
+	cpufreq, policies, err := discoverCpufreq()
+	assert.Nil(t, err, err)
+	// Governors differ between policies
+	assert.Equal(t, map[string]string{"driver": "acpi-cpufreq", "boost": "true"}, cpufreq)
+	assert.Len(t, policies, 2)
+	assert.Equal(t, "0,1", policies[0].Attributes["cpus"])
+	assert.Equal(t, "3500000", policies[0].Attributes["max_freq"])
+
+	// Empty values are ignored
+	testutils.WriteFiles(t, sysfs, map[string]string{
+		"devices/system/cpu/cpufreq/policy0/scaling_driver": "",
+		"devices/system/cpu/cpufreq/policy2/scaling_driver": "",
+	})
+	cpufreq, policies, err = discoverCpufreq()
+	assert.Nil(t, err, err)
+	assert.Equal(t, map[string]string{"boost": "true"}, cpufreq)
+	assert.NotContains(t, policies[0].Attributes, "driver")
+
+	cpuidle, states, err := discoverCpuidle()
+	assert.Nil(t, err, err)
+	assert.Equal(t, map[string]string{"driver": "acpi_idle", "governor": "menu"}, cpuidle)
+	assert.Len(t, states, 3)
+	assert.Equal(t, "C1", states[1].Attributes["name"])
+	assert.Equal(t, "1", states[2].Attributes["disable"])
+
+	// No cpuidle driver in use
+	testutils.WriteFiles(t, sysfs, map[string]string{"devices/system/cpu/cpuidle/current_driver": "none"})
+	cpuidle, _, err = discoverCpuidle()
+	assert.Nil(t, err, err)
+	assert.Equal(t, map[string]string{"governor": "menu"}, cpuidle)
+}
+
+func TestResctrl(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+
+	features := map[string]string{}
+	discoverResctrl(features)
+	assert.Equal(t, map[string]string{"resctrl_mounted": "false"}, features)
+
+	files := map[string]string{
+		"fs/resctrl/info/L3/num_closids":    "16",
+		"fs/resctrl/info/MB/bandwidth_gran": "10",
+		"fs/resctrl/info/MB/min_bandwidth":  "10",
+		"fs/resctrl/info/L3_MON/num_rmids":  "224",
+	}
+	testutils.WriteFiles(t, sysfs, files)
+
+	features = map[string]string{}
+	discoverResctrl(features)
+	expected := map[string]string{
+		"resctrl_mounted":    "true",
+		"resctrl_schemata":   "L3,MB",
+		"resctrl_rmid_count": "224",
+		"mba_granularity":    "10",
+		"mba_min_bandwidth":  "10",
+	}
+	assert.Equal(t, expected, features)
+}
+
+func TestParseMidr(t *testing.T) {
+	// Neoverse-N1 r3p1
+	model, err := parseMidr("0x00000000413fd0c1")
+	assert.Nil(t, err, err)
+	expected := map[string]string{
+		"vendor_id": "0x41",
+		"vendor":    "ARM",
+		"variant":   "0x3",
+		"family":    "15",
+		"id":        "0xd0c",
+		"name":      "Neoverse-N1",
+		"stepping":  "1",
+	}
+	assert.Equal(t, expected, model)
+
+	// Unknown part
+	model, err = parseMidr("0x00000000c00fa001")
+	assert.Nil(t, err, err)
+	assert.Equal(t, "Ampere", model["vendor"])
+	assert.Equal(t, "0xa00", model["id"])
+	assert.NotContains(t, model, "name")
+
+	_, err = parseMidr("foo")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// midrImplementers contains the names of known arm64 cpu implementers
+var midrImplementers = map[uint64]string{
+	0x41: "ARM",
+	0x42: "Broadcom",
+	0x43: "Cavium",
+	0x46: "Fujitsu",
+	0x48: "HiSilicon",
+	0x4e: "NVIDIA",
+	0x50: "APM",
+	0x51: "Qualcomm",
+	0x61: "Apple",
+	0xc0: "Ampere",
+}
+
+// midrParts contains the names of known arm64 cpu parts, by implementer
+var midrParts = map[uint64]map[uint64]string{
+	0x41: {
+		0xd03: "Cortex-A53",
+		0xd07: "Cortex-A57",
+		0xd08: "Cortex-A72",
+		0xd09: "Cortex-A73",
+		0xd0b: "Cortex-A76",
+		0xd0c: "Neoverse-N1",
+		0xd0d: "Cortex-A77",
+		0xd40: "Neoverse-V1",
+		0xd41: "Cortex-A78",
+		0xd49: "Neoverse-N2",
+		0xd4f: "Neoverse-V2",
+	},
+	0x43: {
+		0x0af: "ThunderX2",
+	},
+	0x46: {
+		0x001: "A64FX",
+	},
+	0x48: {
+		0xd01: "TSV110",
+	},
+	0x50: {
+		0x000: "X-Gene",
+	},
+	0xc0: {
+		0xac3: "Ampere-1",
+		0xac4: "Ampere-1a",
+	},
+}
+
+// readMidr reads the MIDR_EL1 register of the first cpu from sysfs and
+// returns the cpu model information decoded from it
+func readMidr() (map[string]string, error) {
+	v, err := readSysfsString(source.SysfsDir.Path("devices/system/cpu/cpu0/regs/identification/midr_el1"))
+	if err != nil {
+		return nil, err
+	}
+	return parseMidr(v)
+}
+
+// parseMidr decodes a MIDR_EL1 register value, e.g. "0x00000000410fd0c1"
+func parseMidr(value string) (map[string]string, error) {
+	midr, err := strconv.ParseUint(strings.TrimPrefix(value, "0x"), 16, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid midr value %q: %w", value, err)
+	}
+
+	implementer := midr >> 24 & 0xff
+	part := midr >> 4 & 0xfff
+	model := map[string]string{
+		"vendor_id": fmt.Sprintf("0x%02x", implementer),
+		"variant":   fmt.Sprintf("0x%x", midr>>20&0xf),
+		"family":    strconv.FormatUint(midr>>16&0xf, 10),
+		"id":        fmt.Sprintf("0x%03x", part),
+		"stepping":  strconv.FormatUint(midr&0xf, 10),
+	}
+	if name, ok := midrImplementers[implementer]; ok {
+		model["vendor"] = name
+	}
+	if name, ok := midrParts[implementer][part]; ok {
+		model["name"] = name
+	}
+	return model, nil
+}
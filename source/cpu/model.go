@@ -0,0 +1,55 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"bufio"
+	"bytes"
+	"io/ioutil"
+	"strings"
+)
+
+const cpuinfoProcfsPath = "/proc/cpuinfo"
+
+// readCpuinfo returns the fields of /proc/cpuinfo
+func readCpuinfo() (map[string]string, error) {
+	data, err := ioutil.ReadFile(cpuinfoProcfsPath)
+	if err != nil {
+		return nil, err
+	}
+	return parseCpuinfo(data), nil
+}
+
+// parseCpuinfo parses the "key : value" lines of /proc/cpuinfo. Only the
+// first occurrence of each key (i.e. the value of the first processor) is
+// stored.
+func parseCpuinfo(data []byte) map[string]string {
+	info := make(map[string]string)
+
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		split := strings.SplitN(s.Text(), ":", 2)
+		if len(split) != 2 {
+			continue
+		}
+		key := strings.TrimSpace(split[0])
+		if _, ok := info[key]; !ok && key != "" {
+			info[key] = strings.TrimSpace(split[1])
+		}
+	}
+	return info
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"strconv"
+
+	"github.com/klauspost/cpuid/v2"
+	"k8s.io/klog/v2"
+)
+
+// getCpuModel returns the model information of the cpu
+func getCpuModel() map[string]string {
+	model := map[string]string{
+		"vendor_id":  cpuid.CPU.VendorString,
+		"family":     strconv.Itoa(cpuid.CPU.Family),
+		"id":         strconv.Itoa(cpuid.CPU.Model),
+		"name":       cpuid.CPU.BrandName,
+		"hypervisor": strconv.FormatBool(cpuid.CPU.Supports(cpuid.HYPERVISOR)),
+	}
+
+	// Stepping and microcode revision are not available from cpuid
+	info, err := readCpuinfo()
+	if err != nil {
+		klog.Errorf("failed to read cpuinfo: %v", err)
+		return model
+	}
+	if v, ok := info["stepping"]; ok {
+		model["stepping"] = v
+	}
+	if v, ok := info["microcode"]; ok {
+		model["microcode"] = v
+	}
+
+	return model
+}
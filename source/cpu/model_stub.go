@@ -0,0 +1,99 @@
+//go:build !amd64
+// +build !amd64
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// cpuinfoModelFields maps the /proc/cpuinfo fields of each architecture to
+// cpu model feature elements
+var cpuinfoModelFields = map[string]map[string]string{
+	"arm64": {
+		"CPU implementer":  "vendor_id",
+		"CPU architecture": "family",
+		"CPU part":         "id",
+		"CPU revision":     "stepping",
+		"model name":       "name",
+	},
+	"ppc64le": {
+		"cpu":      "name",
+		"revision": "stepping",
+	},
+	"s390x": {
+		"vendor_id": "vendor_id",
+	},
+}
+
+// getCpuModel returns the model information of the cpu
+func getCpuModel() map[string]string {
+	model := make(map[string]string)
+
+	info, err := readCpuinfo()
+	if err != nil {
+		klog.Errorf("failed to read cpuinfo: %v", err)
+		return model
+	}
+	for field, name := range cpuinfoModelFields[runtime.GOARCH] {
+		if v, ok := info[field]; ok {
+			model[name] = v
+		}
+	}
+
+	switch runtime.GOARCH {
+	case "arm64":
+		// MIDR is more accurate than cpuinfo and also identifies the part
+		if midr, err := readMidr(); err != nil {
+			klog.V(1).Infof("failed to read midr: %v", err)
+		} else {
+			for k, v := range midr {
+				model[k] = v
+			}
+		}
+	case "ppc64le":
+		// E.g. "POWER9 (architected), altivec supported"
+		model["vendor_id"] = "IBM"
+		if f := strings.Fields(model["name"]); len(f) > 0 {
+			model["family"] = f[0]
+		}
+	case "s390x":
+		// E.g. "version = FF,  identification = 0133E8,  machine = 3906"
+		for _, f := range strings.Split(info["processor 0"], ",") {
+			split := strings.SplitN(f, "=", 2)
+			if len(split) == 2 && strings.TrimSpace(split[0]) == "machine" {
+				model["id"] = strings.TrimSpace(split[1])
+			}
+		}
+	}
+
+	// The hypervisor sysfs interface only exists when running under a
+	// hypervisor that provides it
+	_, err = os.Stat(source.SysfsDir.Path("hypervisor/type"))
+	model["hypervisor"] = strconv.FormatBool(err == nil)
+
+	return model
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// cpufreqPolicyAttrs are the sysfs attributes of cpufreq policies that are
+// read into cpufreq_policy features, mapped to feature attribute names
+var cpufreqPolicyAttrs = map[string]string{
+	"scaling_driver":                "driver",
+	"scaling_governor":              "governor",
+	"scaling_available_governors":   "available_governors",
+	"cpuinfo_min_freq":              "min_freq",
+	"cpuinfo_max_freq":              "max_freq",
+	"base_frequency":                "base_freq",
+	"scaling_min_freq":              "scaling_min_freq",
+	"scaling_max_freq":              "scaling_max_freq",
+	"energy_performance_preference": "energy_performance_preference",
+}
+
+// cpuidleStateAttrs are the sysfs attributes of cpuidle states that are read
+// into cpuidle_state features
+var cpuidleStateAttrs = []string{"name", "desc", "latency", "residency", "disable"}
+
+// discoverCpufreq detects cpu frequency scaling features, independent of the
+// cpufreq driver in use. Returns system-wide features and the features of
+// each cpufreq policy (i.e. group of cpus).
+func discoverCpufreq() (map[string]string, []feature.InstanceFeature, error) {
+	cpufreqDir := source.SysfsDir.Path("devices/system/cpu/cpufreq")
+	policies, err := ioutil.ReadDir(cpufreqDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			klog.V(1).Info("cpufreq not available")
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	instances := []feature.InstanceFeature{}
+	drivers := make(map[string]struct{})
+	governors := make(map[string]struct{})
+	for _, policy := range policies {
+		if !strings.HasPrefix(policy.Name(), "policy") {
+			continue
+		}
+		dir := filepath.Join(cpufreqDir, policy.Name())
+
+		cpus, err := readSysfsString(filepath.Join(dir, "affected_cpus"))
+		if err != nil {
+			klog.Errorf("could not read cpufreq policy %s affected_cpus: %v", policy.Name(), err)
+			continue
+		}
+		if cpus == "" {
+			// Policy has no online cpus
+			continue
+		}
+
+		attrs := map[string]string{
+			"policy": strings.TrimPrefix(policy.Name(), "policy"),
+			"cpus":   strings.Join(strings.Fields(cpus), ","),
+		}
+		for file, name := range cpufreqPolicyAttrs {
+			if v, err := readSysfsString(filepath.Join(dir, file)); err == nil && v != "" {
+				attrs[name] = v
+			}
+		}
+		drivers[attrs["driver"]] = struct{}{}
+		governors[attrs["governor"]] = struct{}{}
+
+		instances = append(instances, *feature.NewInstanceFeature(attrs))
+	}
+
+	// Only set if all policies use the same (known) driver and governor
+	features := make(map[string]string)
+	if len(drivers) == 1 {
+		for d := range drivers {
+			if d != "" {
+				features["driver"] = d
+			}
+		}
+	}
+	if len(governors) == 1 {
+		for g := range governors {
+			if g != "" {
+				features["governor"] = g
+			}
+		}
+	}
+	if boost, ok := detectBoost(); ok {
+		features["boost"] = strconv.FormatBool(boost)
+	}
+
+	return features, instances, nil
+}
+
+// detectBoost detects if frequency boosting (e.g. turbo boost) is enabled
+func detectBoost() (bool, bool) {
+	// Global boost setting (e.g. acpi-cpufreq and amd-pstate drivers)
+	if v, err := readSysfsString(source.SysfsDir.Path("devices/system/cpu/cpufreq/boost")); err == nil {
+		return v == "1", true
+	}
+	// intel_pstate has a setting of its own
+	if v, err := readSysfsString(source.SysfsDir.Path("devices/system/cpu/intel_pstate/no_turbo")); err == nil {
+		return v == "0", true
+	}
+	return false, false
+}
+
+// discoverCpuidle detects cpu idle state features, independent of the
+// cpuidle driver in use. Returns system-wide features and the features of
+// each idle state.
+func discoverCpuidle() (map[string]string, []feature.InstanceFeature, error) {
+	cpuidleDir := source.SysfsDir.Path("devices/system/cpu/cpuidle")
+	if _, err := os.Stat(cpuidleDir); os.IsNotExist(err) {
+		klog.V(1).Info("cpuidle disabled in the kernel")
+		return nil, nil, nil
+	}
+
+	features := make(map[string]string)
+	if v, err := readSysfsString(filepath.Join(cpuidleDir, "current_driver")); err == nil && v != "" && v != "none" {
+		features["driver"] = v
+	}
+	for _, f := range []string{"current_governor", "current_governor_ro"} {
+		if v, err := readSysfsString(filepath.Join(cpuidleDir, f)); err == nil {
+			features["governor"] = v
+			break
+		}
+	}
+
+	// Idle states are (practically) the same on all cpus, use the first one
+	statesDir := source.SysfsDir.Path("devices/system/cpu/cpu0/cpuidle")
+	states, err := ioutil.ReadDir(statesDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return features, nil, nil
+		}
+		return features, nil, err
+	}
+
+	instances := []feature.InstanceFeature{}
+	for _, state := range states {
+		if !strings.HasPrefix(state.Name(), "state") {
+			continue
+		}
+		attrs := map[string]string{"index": strings.TrimPrefix(state.Name(), "state")}
+		for _, a := range cpuidleStateAttrs {
+			if v, err := readSysfsString(filepath.Join(statesDir, state.Name(), a)); err == nil {
+				attrs[a] = v
+			}
+		}
+		instances = append(instances, *feature.NewInstanceFeature(attrs))
+	}
+	sort.Slice(instances, func(i, j int) bool {
+		a, _ := strconv.Atoi(instances[i].Attributes["index"])
+		b, _ := strconv.Atoi(instances[j].Attributes["index"])
+		return a < b
+	})
+
+	return features, instances, nil
+}
@@ -20,6 +20,9 @@ limitations under the License.
 package cpu
 
 import (
+	"fmt"
+	"strconv"
+
 	"sigs.k8s.io/node-feature-discovery/pkg/cpuid"
 )
 
@@ -31,6 +34,9 @@ const (
 
 	// CPUID ECX input values
 	RDT_MONITORING_SUBLEAF_L3 = 1
+	RDT_ALLOCATION_SUBLEAF_L3 = 1
+	RDT_ALLOCATION_SUBLEAF_L2 = 2
+	RDT_ALLOCATION_SUBLEAF_MB = 3
 
 	// CPUID bitmasks
 	EXT_FEATURE_FLAGS_EBX_RDT_M                                 = 1 << 12
@@ -42,6 +48,11 @@ const (
 	RDT_ALLOCATION_EBX_L3_CACHE_ALLOCATION                      = 1 << 1
 	RDT_ALLOCATION_EBX_L2_CACHE_ALLOCATION                      = 1 << 2
 	RDT_ALLOCATION_EBX_MEMORY_BANDWIDTH_ALLOCATION              = 1 << 3
+	RDT_ALLOCATION_SUBLEAF_CAT_EAX_CBM_LEN                      = 0x1f
+	RDT_ALLOCATION_SUBLEAF_CAT_ECX_CDP                          = 1 << 2
+	RDT_ALLOCATION_SUBLEAF_MB_EAX_MAX_THROTTLE                  = 0xfff
+	RDT_ALLOCATION_SUBLEAF_MB_ECX_LINEAR                        = 1 << 2
+	RDT_ALLOCATION_EDX_HIGHEST_COS                              = 0xffff
 )
 
 func discoverRDT() []string {
@@ -89,3 +100,49 @@ func discoverRDT() []string {
 
 	return features
 }
+
+// discoverRDTCapacity detects the capacities of the supported RDT
+// capabilities, e.g. the number of CLOSIDs and RMIDs
+func discoverRDTCapacity() map[string]string {
+	features := make(map[string]string)
+
+	extFeatures := cpuid.Cpuid(LEAF_EXT_FEATURE_FLAGS, 0)
+
+	// Monitoring
+	if extFeatures.EBX&EXT_FEATURE_FLAGS_EBX_RDT_M != 0 {
+		rdtMonitoring := cpuid.Cpuid(LEAF_RDT_MONITORING, 0)
+		if rdtMonitoring.EDX&RDT_MONITORING_EDX_L3_MONITORING != 0 {
+			rdtL3Monitoring := cpuid.Cpuid(LEAF_RDT_MONITORING, RDT_MONITORING_SUBLEAF_L3)
+			features["l3_rmid_count"] = strconv.FormatUint(uint64(rdtL3Monitoring.ECX)+1, 10)
+		}
+	}
+
+	// Allocation
+	if extFeatures.EBX&EXT_FEATURE_FLAGS_EBX_RDT_A != 0 {
+		rdtAllocation := cpuid.Cpuid(LEAF_RDT_ALLOCATION, 0)
+
+		cat := func(prefix string, subleaf uint32) {
+			r := cpuid.Cpuid(LEAF_RDT_ALLOCATION, subleaf)
+			cbmLen := r.EAX&RDT_ALLOCATION_SUBLEAF_CAT_EAX_CBM_LEN + 1
+			features[prefix+"_closid_count"] = strconv.FormatUint(uint64(r.EDX&RDT_ALLOCATION_EDX_HIGHEST_COS)+1, 10)
+			features[prefix+"_cbm_length"] = strconv.FormatUint(uint64(cbmLen), 10)
+			features[prefix+"_cbm_mask"] = fmt.Sprintf("%x", uint64(1)<<cbmLen-1)
+			features[prefix+"_shareable_mask"] = fmt.Sprintf("%x", r.EBX)
+			features[prefix+"_cdp"] = strconv.FormatBool(r.ECX&RDT_ALLOCATION_SUBLEAF_CAT_ECX_CDP != 0)
+		}
+		if rdtAllocation.EBX&RDT_ALLOCATION_EBX_L3_CACHE_ALLOCATION != 0 {
+			cat("l3", RDT_ALLOCATION_SUBLEAF_L3)
+		}
+		if rdtAllocation.EBX&RDT_ALLOCATION_EBX_L2_CACHE_ALLOCATION != 0 {
+			cat("l2", RDT_ALLOCATION_SUBLEAF_L2)
+		}
+		if rdtAllocation.EBX&RDT_ALLOCATION_EBX_MEMORY_BANDWIDTH_ALLOCATION != 0 {
+			r := cpuid.Cpuid(LEAF_RDT_ALLOCATION, RDT_ALLOCATION_SUBLEAF_MB)
+			features["mba_closid_count"] = strconv.FormatUint(uint64(r.EDX&RDT_ALLOCATION_EDX_HIGHEST_COS)+1, 10)
+			features["mba_max_throttle"] = strconv.FormatUint(uint64(r.EAX&RDT_ALLOCATION_SUBLEAF_MB_EAX_MAX_THROTTLE)+1, 10)
+			features["mba_linear"] = strconv.FormatBool(r.ECX&RDT_ALLOCATION_SUBLEAF_MB_ECX_LINEAR != 0)
+		}
+	}
+
+	return features
+}
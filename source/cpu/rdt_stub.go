@@ -22,3 +22,7 @@ package cpu
 func discoverRDT() []string {
 	return []string{}
 }
+
+func discoverRDTCapacity() map[string]string {
+	return map[string]string{}
+}
@@ -0,0 +1,68 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// resctrlInfoAttrs are the resctrl info files read into rdt_capacity
+// features, mapped by resource
+var resctrlInfoAttrs = map[string]map[string]string{
+	"MB": {
+		"bandwidth_gran": "mba_granularity",
+		"min_bandwidth":  "mba_min_bandwidth",
+	},
+}
+
+// discoverResctrl detects the status of the resctrl filesystem, adding the
+// findings to the given rdt capacity features
+func discoverResctrl(features map[string]string) {
+	infoDir := source.SysfsDir.Path("fs/resctrl/info")
+
+	resources, err := ioutil.ReadDir(infoDir)
+	if err != nil {
+		// The info directory only exists if resctrl is mounted
+		features["resctrl_mounted"] = "false"
+		return
+	}
+	features["resctrl_mounted"] = "true"
+
+	schemata := []string{}
+	for _, r := range resources {
+		if !r.IsDir() || strings.HasSuffix(r.Name(), "_MON") {
+			continue
+		}
+		schemata = append(schemata, r.Name())
+		for file, name := range resctrlInfoAttrs[r.Name()] {
+			if v, err := readSysfsString(filepath.Join(infoDir, r.Name(), file)); err == nil {
+				features[name] = v
+			}
+		}
+	}
+	sort.Strings(schemata)
+	features["resctrl_schemata"] = strings.Join(schemata, ",")
+
+	if v, err := readSysfsString(filepath.Join(infoDir, "L3_MON", "num_rmids")); err == nil {
+		features["resctrl_rmid_count"] = v
+	}
+}
@@ -0,0 +1,336 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+func discoverTopology() map[string]string {
+	features := make(map[string]string)
+
+	if ht, err := haveThreadSiblings(); err != nil {
+		klog.Errorf("failed to detect hyper-threading: %v", err)
+	} else {
+		features["hardware_multithreading"] = strconv.FormatBool(ht)
+	}
+
+	if err := discoverCoreCounts(features); err != nil {
+		klog.Errorf("failed to detect cpu topology: %v", err)
+	}
+
+	// Cache sizes, as seen by the first cpu
+	caches, err := readCpuCaches("cpu0")
+	if err != nil {
+		klog.V(1).Infof("failed to detect cpu caches: %v", err)
+	}
+	for _, c := range caches {
+		features[c.name()+"_cache_size"] = c.Attributes["size"]
+	}
+
+	return features
+}
+
+// Check if any (online) CPUs have thread siblings
+func haveThreadSiblings() (bool, error) {
+
+	files, err := ioutil.ReadDir(source.SysfsDir.Path("bus/cpu/devices"))
+	if err != nil {
+		return false, err
+	}
+
+	for _, file := range files {
+		// Try to read siblings from topology
+		siblings, err := ioutil.ReadFile(source.SysfsDir.Path("bus/cpu/devices", file.Name(), "topology/thread_siblings_list"))
+		if err != nil {
+			return false, err
+		}
+		for _, char := range siblings {
+			// If list separator found, we determine that there are multiple siblings
+			if char == ',' || char == '-' {
+				return true, nil
+			}
+		}
+	}
+	// No siblings were found
+	return false, nil
+}
+
+// discoverCoreCounts detects the number of sockets, dies, cores and threads
+// of the (online) cpus. Per-socket and per-core counts are computed by grouping
+// the cpus, reporting the maximum in case the topology is asymmetric, e.g. with
+// hybrid cores or offlined cpus.
+func discoverCoreCounts(features map[string]string) error {
+	files, err := ioutil.ReadDir(source.SysfsDir.Path("bus/cpu/devices"))
+	if err != nil {
+		return err
+	}
+
+	// Dies of each socket, cores of each socket and threads of each core
+	dies := make(map[string]map[string]struct{})
+	cores := make(map[string]map[string]struct{})
+	threads := make(map[string]int)
+	cpus := 0
+	for _, file := range files {
+		topologyDir := source.SysfsDir.Path("bus/cpu/devices", file.Name(), "topology")
+		if _, err := os.Stat(topologyDir); os.IsNotExist(err) {
+			// Offline cpus do not have topology information
+			continue
+		}
+		pkg, err := readSysfsString(filepath.Join(topologyDir, "physical_package_id"))
+		if err != nil {
+			return err
+		}
+		core, err := readSysfsString(filepath.Join(topologyDir, "core_id"))
+		if err != nil {
+			return err
+		}
+		// die_id is not available on all architectures and kernel versions
+		die, err := readSysfsString(filepath.Join(topologyDir, "die_id"))
+		if err != nil || die == "-1" {
+			die = "0"
+		}
+
+		if _, ok := dies[pkg]; !ok {
+			dies[pkg] = make(map[string]struct{})
+			cores[pkg] = make(map[string]struct{})
+		}
+		dies[pkg][die] = struct{}{}
+		cores[pkg][die+"/"+core] = struct{}{}
+		threads[pkg+"/"+die+"/"+core]++
+		cpus++
+	}
+	if cpus == 0 {
+		return fmt.Errorf("no cpu topology information found")
+	}
+
+	dieCount := 0
+	uniform := true
+	maxCount := func(prev, count int) int {
+		if prev != 0 && prev != count {
+			uniform = false
+		}
+		if count > prev {
+			return count
+		}
+		return prev
+	}
+	diesPerSocket, coresPerSocket, threadsPerCore := 0, 0, 0
+	for pkg := range dies {
+		dieCount += len(dies[pkg])
+		diesPerSocket = maxCount(diesPerSocket, len(dies[pkg]))
+		coresPerSocket = maxCount(coresPerSocket, len(cores[pkg]))
+	}
+	for _, n := range threads {
+		threadsPerCore = maxCount(threadsPerCore, n)
+	}
+
+	features["cpu_count"] = strconv.Itoa(cpus)
+	features["socket_count"] = strconv.Itoa(len(dies))
+	features["die_count"] = strconv.Itoa(dieCount)
+	features["dies_per_socket"] = strconv.Itoa(diesPerSocket)
+	features["cores_per_socket"] = strconv.Itoa(coresPerSocket)
+	features["threads_per_core"] = strconv.Itoa(threadsPerCore)
+	features["uniform_topology"] = strconv.FormatBool(uniform)
+
+	return nil
+}
+
+// discoverNuma returns the cpus of each NUMA node
+func discoverNuma() ([]feature.InstanceFeature, error) {
+	nodes, err := ioutil.ReadDir(source.SysfsDir.Path("bus/node/devices"))
+	if err != nil {
+		return nil, err
+	}
+
+	instances := make([]feature.InstanceFeature, 0, len(nodes))
+	for _, node := range nodes {
+		id := strings.TrimPrefix(node.Name(), "node")
+		cpuList, err := readSysfsString(source.SysfsDir.Path("bus/node/devices", node.Name(), "cpulist"))
+		if err != nil {
+			return nil, err
+		}
+		count, err := cpuListCount(cpuList)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cpulist of numa node %s: %w", id, err)
+		}
+		instances = append(instances, *feature.NewInstanceFeature(map[string]string{
+			"node":      id,
+			"cpus":      cpuList,
+			"cpu_count": strconv.Itoa(count),
+		}))
+	}
+	return instances, nil
+}
+
+// cacheInfo describes one cpu cache
+type cacheInfo struct {
+	feature.InstanceFeature
+}
+
+// name returns the short name of the cache, e.g. "l1d" or "l3"
+func (c cacheInfo) name() string {
+	n := "l" + c.Attributes["level"]
+	switch c.Attributes["type"] {
+	case "data":
+		n += "d"
+	case "instruction":
+		n += "i"
+	}
+	return n
+}
+
+// discoverCaches returns all distinct cpu caches of the system
+func discoverCaches() ([]feature.InstanceFeature, error) {
+	files, err := ioutil.ReadDir(source.SysfsDir.Path("bus/cpu/devices"))
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]struct{})
+	instances := []feature.InstanceFeature{}
+	for _, file := range files {
+		caches, err := readCpuCaches(file.Name())
+		if err != nil {
+			return nil, err
+		}
+		for _, c := range caches {
+			// The same cache is listed under all cpus sharing it
+			key := c.name() + "/" + c.Attributes["shared_cpu_list"]
+			if _, ok := seen[key]; !ok {
+				seen[key] = struct{}{}
+				instances = append(instances, c.InstanceFeature)
+			}
+		}
+	}
+	return instances, nil
+}
+
+// readCpuCaches reads the caches of one cpu from sysfs
+func readCpuCaches(cpu string) ([]cacheInfo, error) {
+	cacheDir := source.SysfsDir.Path("bus/cpu/devices", cpu, "cache")
+	indices, err := ioutil.ReadDir(cacheDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	caches := []cacheInfo{}
+	for _, index := range indices {
+		if !strings.HasPrefix(index.Name(), "index") {
+			continue
+		}
+		dir := filepath.Join(cacheDir, index.Name())
+
+		attrs := make(map[string]string)
+		for _, a := range []string{"level", "type", "size", "shared_cpu_list"} {
+			v, err := readSysfsString(filepath.Join(dir, a))
+			if err != nil {
+				klog.V(2).Infof("skipping cache %s: %v", dir, err)
+				attrs = nil
+				break
+			}
+			attrs[a] = v
+		}
+		if attrs == nil {
+			continue
+		}
+
+		attrs["type"] = strings.ToLower(attrs["type"])
+		size, err := parseCacheSize(attrs["size"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid size of cache %s: %w", dir, err)
+		}
+		attrs["size"] = strconv.FormatInt(size, 10)
+		count, err := cpuListCount(attrs["shared_cpu_list"])
+		if err != nil {
+			return nil, fmt.Errorf("invalid shared_cpu_list of cache %s: %w", dir, err)
+		}
+		attrs["shared_cpu_count"] = strconv.Itoa(count)
+
+		caches = append(caches, cacheInfo{*feature.NewInstanceFeature(attrs)})
+	}
+
+	sort.Slice(caches, func(i, j int) bool { return caches[i].name() < caches[j].name() })
+
+	return caches, nil
+}
+
+// parseCacheSize parses a cache size from sysfs, e.g. "32K", into bytes
+func parseCacheSize(s string) (int64, error) {
+	mult := int64(1)
+	switch {
+	case strings.HasSuffix(s, "K"):
+		mult = 1024
+	case strings.HasSuffix(s, "M"):
+		mult = 1024 * 1024
+	case strings.HasSuffix(s, "G"):
+		mult = 1024 * 1024 * 1024
+	}
+	v, err := strconv.ParseInt(strings.TrimRight(s, "KMG"), 10, 64)
+	if err != nil {
+		return 0, err
+	}
+	return v * mult, nil
+}
+
+// cpuListCount returns the number of cpus in a cpu list, e.g. "0-3,8"
+func cpuListCount(list string) (int, error) {
+	count := 0
+	if list == "" {
+		return 0, nil
+	}
+	for _, r := range strings.Split(list, ",") {
+		split := strings.SplitN(r, "-", 2)
+		first, err := strconv.Atoi(split[0])
+		if err != nil {
+			return 0, err
+		}
+		last := first
+		if len(split) == 2 {
+			if last, err = strconv.Atoi(split[1]); err != nil {
+				return 0, err
+			}
+		}
+		if last < first {
+			return 0, fmt.Errorf("invalid range %q", r)
+		}
+		count += last - first + 1
+	}
+	return count, nil
+}
+
+func readSysfsString(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import "strconv"
+
+// x86_64LevelFlags are the cpuid flags required by each x86-64 psABI
+// microarchitecture level (v1 to v4), in addition to the flags of the lower
+// levels. Requirements that are not detected by the cpuid library (e.g.
+// LAHF-SAHF, MOVBE and OSXSAVE) are omitted.
+var x86_64LevelFlags = [][]string{
+	{"CMOV", "MMX", "SSE", "SSE2"},
+	{"CX16", "POPCNT", "SSE3", "SSE4", "SSE42", "SSSE3"},
+	{"AVX", "AVX2", "BMI1", "BMI2", "F16C", "FMA3", "LZCNT"},
+	{"AVX512F", "AVX512BW", "AVX512CD", "AVX512DQ", "AVX512VL"},
+}
+
+// discoverX86_64Level determines the x86-64 microarchitecture level
+// supported by the cpu, based on the given cpuid flags
+func discoverX86_64Level(cpuidFlags []string) map[string]string {
+	flags := make(map[string]struct{}, len(cpuidFlags))
+	for _, f := range cpuidFlags {
+		flags[f] = struct{}{}
+	}
+
+	level := 0
+	for _, required := range x86_64LevelFlags {
+		for _, f := range required {
+			if _, ok := flags[f]; !ok {
+				return x86_64LevelFeatures(level)
+			}
+		}
+		level++
+	}
+	return x86_64LevelFeatures(level)
+}
+
+func x86_64LevelFeatures(level int) map[string]string {
+	if level == 0 {
+		return nil
+	}
+	return map[string]string{"level": strconv.Itoa(level)}
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoverX86_64Level(t *testing.T) {
+	v1 := []string{"CMOV", "MMX", "NX", "SSE", "SSE2"}
+	v2 := append(append([]string{}, v1...), "CX16", "POPCNT", "SSE3", "SSE4", "SSE42", "SSSE3")
+	v3 := append(append([]string{}, v2...), "AVX", "AVX2", "BMI1", "BMI2", "F16C", "FMA3", "LZCNT")
+	v4 := append(append([]string{}, v3...), "AVX512F", "AVX512BW", "AVX512CD", "AVX512DQ", "AVX512VL")
+
+	assert.Nil(t, discoverX86_64Level([]string{"SSE"}))
+	assert.Equal(t, map[string]string{"level": "1"}, discoverX86_64Level(v1))
+	assert.Equal(t, map[string]string{"level": "2"}, discoverX86_64Level(v2))
+	assert.Equal(t, map[string]string{"level": "3"}, discoverX86_64Level(v3))
+	assert.Equal(t, map[string]string{"level": "4"}, discoverX86_64Level(v4))
+
+	// Missing a v3 flag
+	assert.Equal(t, map[string]string{"level": "2"}, discoverX86_64Level(append(append([]string{}, v2...), "AVX", "AVX2", "BMI1", "BMI2", "F16C", "FMA3")))
+}
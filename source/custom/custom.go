@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"reflect"
 	"strings"
+	"text/template"
 
 	"k8s.io/klog/v2"
 	"sigs.k8s.io/yaml"
@@ -80,11 +81,16 @@ var (
 	src                           = customSource{config: newDefaultConfig()}
 	_   source.LabelSource        = &src
 	_   source.ConfigurableSource = &src
+	_   source.ValidatingSource   = &src
+	_   source.WatchableSource    = &src
 )
 
 // Name returns the name of the feature source
 func (s *customSource) Name() string { return Name }
 
+// WatchPaths method of the WatchableSource interface
+func (s *customSource) WatchPaths() []string { return []string{Directory} }
+
 // NewConfig method of the LabelSource interface
 func (s *customSource) NewConfig() source.Config { return newDefaultConfig() }
 
@@ -101,6 +107,36 @@ func (s *customSource) SetConfig(conf source.Config) {
 	}
 }
 
+// ValidateConfig method of the ValidatingSource interface
+func (s *customSource) ValidateConfig(conf source.Config) []error {
+	c, ok := conf.(*config)
+	if !ok {
+		return []error{fmt.Errorf("invalid config type: %T", conf)}
+	}
+
+	return validateRules(*c)
+}
+
+// validateRules checks a list of custom rules for errors.
+func validateRules(rules []CustomRule) []error {
+	var errs []error
+	for i, r := range rules {
+		var err error
+		switch {
+		case r.LegacyRule != nil:
+			err = r.LegacyRule.validate()
+		case r.Rule != nil:
+			err = r.Rule.validate()
+		default:
+			err = fmt.Errorf("empty rule")
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid rule #%d: %w", i, err))
+		}
+	}
+	return errs
+}
+
 // Priority method of the LabelSource interface
 func (s *customSource) Priority() int { return 10 }
 
@@ -154,6 +190,21 @@ func (r *CustomRule) execute(features map[string]*feature.DomainFeatures) (nfdv1
 	return nfdv1alpha1.RuleOutput{}, fmt.Errorf("BUG: an empty rule, this really should not happen")
 }
 
+func (r *LegacyRule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name must be specified")
+	}
+	if len(r.MatchOn) == 0 {
+		return fmt.Errorf("%s: matchOn must not be empty", r.Name)
+	}
+	for i, matcher := range r.MatchOn {
+		if reflect.ValueOf(matcher).IsZero() {
+			return fmt.Errorf("%s: matchOn #%d must not be empty", r.Name, i)
+		}
+	}
+	return nil
+}
+
 func (r *LegacyRule) execute(features map[string]*feature.DomainFeatures) (map[string]string, error) {
 	if len(r.MatchOn) > 0 {
 		// Logical OR over the legacy rules
@@ -213,6 +264,41 @@ func (m *LegacyMatcher) match() (bool, error) {
 	return matchRules(allRules)
 }
 
+func (r *Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("name must be specified")
+	}
+
+	validateMatcher := func(m nfdv1alpha1.FeatureMatcher) error {
+		for _, term := range m {
+			split := strings.SplitN(term.Feature, ".", 2)
+			if len(split) != 2 {
+				return fmt.Errorf("invalid feature %q: must be <domain>.<feature>", term.Feature)
+			}
+			if split[0] != nfdv1alpha1.RuleBackrefDomain && source.GetFeatureSource(split[0]) == nil {
+				return fmt.Errorf("unknown feature source/domain %q", split[0])
+			}
+		}
+		return nil
+	}
+
+	if err := validateMatcher(r.MatchFeatures); err != nil {
+		return fmt.Errorf("%s: %w", r.Name, err)
+	}
+	for _, e := range r.MatchAny {
+		if err := validateMatcher(e.MatchFeatures); err != nil {
+			return fmt.Errorf("%s: %w", r.Name, err)
+		}
+	}
+
+	for _, t := range []string{r.LabelsTemplate, r.VarsTemplate} {
+		if _, err := template.New("").Option("missingkey=error").Parse(t); err != nil {
+			return fmt.Errorf("%s: invalid template: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
 // UnmarshalJSON implements the Unmarshaler interface from "encoding/json"
 func (c *CustomRule) UnmarshalJSON(data []byte) error {
 	// Do a raw parse to determine if this is a legacy rule
@@ -17,6 +17,7 @@ limitations under the License.
 package custom
 
 import (
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -29,26 +30,56 @@ import (
 // Directory stores the full path for the custom sources folder
 const Directory = "/etc/kubernetes/node-feature-discovery/custom.d"
 
+// ruleFile holds the rules read from one file in the custom rules directory
+type ruleFile struct {
+	path  string
+	rules []CustomRule
+}
+
 // getDirectoryFeatureConfig returns features configured in the "/etc/kubernetes/node-feature-discovery/custom.d"
 // host directory and its 1st level subdirectories, which can be populated e.g. by ConfigMaps
 func getDirectoryFeatureConfig() []CustomRule {
-	features := readDir(Directory, true)
+	files, errs := readDir(Directory, true)
+	for _, err := range errs {
+		klog.Error(err)
+	}
+
+	features := make([]CustomRule, 0)
+	for _, f := range files {
+		features = append(features, f.rules...)
+	}
 	klog.V(1).Infof("all configmap based custom feature specs: %+v", features)
 	return features
 }
 
-func readDir(dirName string, recursive bool) []CustomRule {
-	features := make([]CustomRule, 0)
+// ValidateDirectory checks the rule files in the custom rules directory for
+// errors, in the same way as the custom rules in the worker configuration.
+func ValidateDirectory() []error {
+	return validateDir(Directory)
+}
+
+func validateDir(dirName string) []error {
+	files, errs := readDir(dirName, true)
+	for _, f := range files {
+		for _, err := range validateRules(f.rules) {
+			errs = append(errs, fmt.Errorf("%s: %w", f.path, err))
+		}
+	}
+	return errs
+}
+
+func readDir(dirName string, recursive bool) ([]ruleFile, []error) {
+	ruleFiles := make([]ruleFile, 0)
+	var errs []error
 
 	klog.V(1).Infof("getting files in %s", dirName)
 	files, err := ioutil.ReadDir(dirName)
 	if err != nil {
 		if os.IsNotExist(err) {
 			klog.V(1).Infof("custom config directory %q does not exist", dirName)
-		} else {
-			klog.Errorf("unable to access custom config directory %q, %v", dirName, err)
+			return ruleFiles, nil
 		}
-		return features
+		return ruleFiles, []error{fmt.Errorf("unable to access custom config directory %q, %v", dirName, err)}
 	}
 
 	for _, file := range files {
@@ -57,7 +88,9 @@ func readDir(dirName string, recursive bool) []CustomRule {
 		if file.IsDir() {
 			if recursive {
 				klog.V(1).Infof("processing dir %q", fileName)
-				features = append(features, readDir(fileName, false)...)
+				subFiles, subErrs := readDir(fileName, false)
+				ruleFiles = append(ruleFiles, subFiles...)
+				errs = append(errs, subErrs...)
 			} else {
 				klog.V(2).Infof("skipping dir %q", fileName)
 			}
@@ -71,7 +104,7 @@ func readDir(dirName string, recursive bool) []CustomRule {
 
 		bytes, err := ioutil.ReadFile(fileName)
 		if err != nil {
-			klog.Errorf("could not read custom config file %q, %v", fileName, err)
+			errs = append(errs, fmt.Errorf("could not read custom config file %q, %v", fileName, err))
 			continue
 		}
 		klog.V(2).Infof("custom config rules raw: %s", string(bytes))
@@ -79,11 +112,11 @@ func readDir(dirName string, recursive bool) []CustomRule {
 		config := &[]CustomRule{}
 		err = yaml.UnmarshalStrict(bytes, config)
 		if err != nil {
-			klog.Errorf("could not parse custom config file %q, %v", fileName, err)
+			errs = append(errs, fmt.Errorf("could not parse custom config file %q, %v", fileName, err))
 			continue
 		}
 
-		features = append(features, *config...)
+		ruleFiles = append(ruleFiles, ruleFile{path: fileName, rules: *config})
 	}
-	return features
+	return ruleFiles, errs
 }
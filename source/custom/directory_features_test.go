@@ -0,0 +1,53 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package custom
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/source/testutils"
+)
+
+func TestValidateDir(t *testing.T) {
+	dir := t.TempDir()
+
+	// Non-existent directory is not an error
+	assert.Empty(t, validateDir(filepath.Join(dir, "missing")))
+
+	testutils.WriteFiles(t, dir, map[string]string{
+		"valid.yaml": `
+- name: "rule-1"
+  matchOn:
+    - loadedKMod: ["kmod-1"]`,
+		"sub/invalid.yaml": `
+- name: "rule-2"
+  matchFeatures:
+    - feature: foo`,
+		"sub/unparsable.yaml":     `- name: [`,
+		"sub/deeper/ignored.yaml": `- matchOn: []`,
+		".hidden":                 `- matchOn: []`,
+	})
+
+	errs := validateDir(dir)
+	if assert.Len(t, errs, 2) {
+		assert.Contains(t, errs[0].Error(), filepath.Join(dir, "sub", "unparsable.yaml"))
+		assert.Contains(t, errs[1].Error(), filepath.Join(dir, "sub", "invalid.yaml")+": invalid rule #0: rule-2")
+	}
+}
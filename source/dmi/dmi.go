@@ -0,0 +1,194 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dmi
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+const Name = "dmi"
+
+const IDFeature = "id"
+
+// dmiIdAttrs are the (world-readable) attributes read from /sys/class/dmi/id
+var dmiIdAttrs = []string{
+	"sys_vendor",
+	"product_name",
+	"product_family",
+	"product_version",
+	"board_vendor",
+	"board_name",
+	"bios_vendor",
+	"bios_version",
+	"bios_date",
+	"chassis_type",
+}
+
+// Config holds the configuration parameters of this source.
+type Config struct {
+	LabelFields []string `json:"labelFields,omitempty"`
+}
+
+// newDefaultConfig returns a new config with pre-populated defaults
+func newDefaultConfig() *Config {
+	return &Config{
+		LabelFields: []string{"sys_vendor", "product_name"},
+	}
+}
+
+// dmiSource implements the FeatureSource, LabelSource and ConfigurableSource interfaces.
+type dmiSource struct {
+	config   *Config
+	features *feature.DomainFeatures
+}
+
+// Singleton source instance
+var (
+	src                           = dmiSource{config: newDefaultConfig()}
+	_   source.FeatureSource      = &src
+	_   source.LabelSource        = &src
+	_   source.ConfigurableSource = &src
+	_   source.ValidatingSource   = &src
+)
+
+// Name returns the name of the feature source
+func (s *dmiSource) Name() string { return Name }
+
+// NewConfig method of the LabelSource interface
+func (s *dmiSource) NewConfig() source.Config { return newDefaultConfig() }
+
+// GetConfig method of the LabelSource interface
+func (s *dmiSource) GetConfig() source.Config { return s.config }
+
+// SetConfig method of the LabelSource interface
+func (s *dmiSource) SetConfig(conf source.Config) {
+	switch v := conf.(type) {
+	case *Config:
+		s.config = v
+	default:
+		klog.Fatalf("invalid config type: %T", conf)
+	}
+}
+
+// ValidateConfig method of the ValidatingSource interface
+func (s *dmiSource) ValidateConfig(conf source.Config) []error {
+	c, ok := conf.(*Config)
+	if !ok {
+		return []error{fmt.Errorf("invalid config type: %T", conf)}
+	}
+
+	errs := []error{}
+	for _, f := range c.LabelFields {
+		if !isValidAttr(f) {
+			errs = append(errs, fmt.Errorf("invalid field %q in labelFields", f))
+		}
+	}
+	return errs
+}
+
+// Priority method of the LabelSource interface
+func (s *dmiSource) Priority() int { return 0 }
+
+// GetLabels method of the LabelSource interface
+func (s *dmiSource) GetLabels() (source.FeatureLabels, error) {
+	labels := source.FeatureLabels{}
+	features := s.GetFeatures()
+
+	for _, f := range s.config.LabelFields {
+		if !isValidAttr(f) {
+			klog.Warningf("invalid field %q in labelFields, ignoring...", f)
+			continue
+		}
+		if value, ok := features.Values[IDFeature].Elements[f]; ok {
+			if v := utils.SanitizeLabelValue(value); v != "" {
+				labels[IDFeature+"."+f] = v
+			}
+		}
+	}
+	return labels, nil
+}
+
+// Discover method of the FeatureSource interface
+func (s *dmiSource) Discover() error {
+	s.features = feature.NewDomainFeatures()
+
+	attrs, err := readDmiId()
+	if err != nil {
+		return err
+	}
+	s.features.Values[IDFeature] = feature.NewValueFeatures(attrs)
+
+	utils.KlogDump(3, "discovered dmi features:", "  ", s.features)
+
+	return nil
+}
+
+// GetFeatures method of the FeatureSource Interface
+func (s *dmiSource) GetFeatures() *feature.DomainFeatures {
+	if s.features == nil {
+		s.features = feature.NewDomainFeatures()
+	}
+	return s.features
+}
+
+// readDmiId reads the known DMI attributes from sysfs
+func readDmiId() (map[string]string, error) {
+	attrs := make(map[string]string)
+
+	basePath := source.SysfsDir.Path("class/dmi/id")
+	if _, err := os.Stat(basePath); err != nil {
+		if os.IsNotExist(err) {
+			klog.V(1).Infof("%s does not exist, DMI not available", basePath)
+			return attrs, nil
+		}
+		return nil, fmt.Errorf("unable to access %s: %w", basePath, err)
+	}
+
+	for _, attr := range dmiIdAttrs {
+		data, err := ioutil.ReadFile(source.SysfsDir.Path("class/dmi/id", attr))
+		if err != nil {
+			klog.V(3).Infof("failed to read dmi attribute %q: %v", attr, err)
+			continue
+		}
+		if v := strings.TrimSpace(string(data)); v != "" {
+			attrs[attr] = v
+		}
+	}
+	return attrs, nil
+}
+
+func isValidAttr(name string) bool {
+	for _, a := range dmiIdAttrs {
+		if a == name {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	source.Register(&src)
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package dmi
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/source"
+	"sigs.k8s.io/node-feature-discovery/source/testutils"
+)
+
+func TestDmiSource(t *testing.T) {
+	assert.Equal(t, src.Name(), Name)
+
+	// Check that GetLabels works with empty features
+	src.features = nil
+	l, err := src.GetLabels()
+
+	assert.Nil(t, err, err)
+	assert.Empty(t, l)
+}
+
+func TestDiscover(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+	testutils.WriteFiles(t, sysfs, map[string]string{
+		"class/dmi/id/sys_vendor":      "Dell Inc.",
+		"class/dmi/id/product_name":    "PowerEdge R750 (SKU=090E;ModelName=PowerEdge R750)",
+		"class/dmi/id/product_version": " ",
+		"class/dmi/id/bios_version":    "1.5.4",
+		"class/dmi/id/chassis_type":    "23",
+		// Not in the list of known attributes, e.g. root-only
+		"class/dmi/id/product_serial": "ABC1234",
+	})
+
+	origConfig := src.config
+	defer func() { src.config = origConfig }()
+
+	assert.Nil(t, src.Discover())
+	expectedAttrs := map[string]string{
+		"sys_vendor":   "Dell Inc.",
+		"product_name": "PowerEdge R750 (SKU=090E;ModelName=PowerEdge R750)",
+		"bios_version": "1.5.4",
+		"chassis_type": "23",
+	}
+	assert.Equal(t, expectedAttrs, src.GetFeatures().Values[IDFeature].Elements)
+
+	// Label values are sanitized
+	src.config = newDefaultConfig()
+	l, err := src.GetLabels()
+	assert.Nil(t, err, err)
+	expected := source.FeatureLabels{
+		"id.sys_vendor":   "Dell_Inc",
+		"id.product_name": "PowerEdge_R750_SKU_090E_ModelName_PowerEdge_R750",
+	}
+	assert.Equal(t, expected, l)
+
+	// Only known fields can be published as labels
+	src.config = &Config{LabelFields: []string{"bios_version", "product_serial", "product_version"}}
+	assert.Len(t, src.ValidateConfig(src.config), 1)
+	l, err = src.GetLabels()
+	assert.Nil(t, err, err)
+	assert.Equal(t, source.FeatureLabels{"id.bios_version": "1.5.4"}, l)
+
+	// Missing DMI support is not an error
+	source.SysfsDir = source.HostDir(filepath.Join(sysfs, "nonexistent"))
+	assert.Nil(t, src.Discover())
+	assert.Empty(t, src.GetFeatures().Values[IDFeature].Elements)
+}
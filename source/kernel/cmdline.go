@@ -0,0 +1,91 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kernel
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+const cmdlineProcfsPath = "/proc/cmdline"
+
+// getCmdline returns the parameters of the kernel command line
+func getCmdline() (map[string]string, error) {
+	data, err := ioutil.ReadFile(cmdlineProcfsPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file %s: %s", cmdlineProcfsPath, err.Error())
+	}
+	return parseCmdline(string(data)), nil
+}
+
+// parseCmdline parses a kernel command line into a map of parameters. Flags
+// (parameters without a value) get the value "true". Values may be quoted with
+// double quotes. The last occurrence wins if a parameter is specified more
+// than once. Arguments after "--" are passed to init and not parsed.
+func parseCmdline(cmdline string) map[string]string {
+	params := make(map[string]string)
+
+	for _, arg := range splitCmdline(cmdline) {
+		if arg == "--" {
+			break
+		}
+
+		split := strings.SplitN(arg, "=", 2)
+		name := split[0]
+		if name == "" {
+			continue
+		}
+		if len(split) == 1 {
+			params[name] = "true"
+		} else {
+			params[name] = split[1]
+		}
+	}
+
+	return params
+}
+
+// splitCmdline splits the kernel command line into whitespace separated
+// arguments, honoring double quotes. The quotes are removed.
+func splitCmdline(cmdline string) []string {
+	args := []string{}
+	var arg strings.Builder
+	inArg, inQuote := false, false
+
+	for _, r := range cmdline {
+		switch {
+		case r == '"':
+			inQuote = !inQuote
+			inArg = true
+		case !inQuote && (r == ' ' || r == '\t' || r == '\n'):
+			if inArg {
+				args = append(args, arg.String())
+				arg.Reset()
+				inArg = false
+			}
+		default:
+			arg.WriteRune(r)
+			inArg = true
+		}
+	}
+	if inArg {
+		args = append(args, arg.String())
+	}
+
+	return args
+}
@@ -29,6 +29,7 @@ import (
 const Name = "kernel"
 
 const (
+	CmdlineFeature      = "cmdline"
 	ConfigFeature       = "config"
 	LoadedModuleFeature = "loadedmodule"
 	SelinuxFeature      = "selinux"
@@ -134,6 +135,13 @@ func (s *kernelSource) Discover() error {
 		s.legacyKconfig = legacyKconfig
 	}
 
+	// Read kernel command line
+	if cmdline, err := getCmdline(); err != nil {
+		klog.Errorf("failed to get kernel command line: %v", err)
+	} else {
+		s.features.Values[CmdlineFeature] = feature.NewValueFeatures(cmdline)
+	}
+
 	if kmods, err := getLoadedModules(); err != nil {
 		klog.Errorf("failed to get loaded kernel modules: %v", err)
 	} else {
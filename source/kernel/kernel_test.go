@@ -33,3 +33,25 @@ func TestKernelSource(t *testing.T) {
 	assert.Empty(t, l)
 
 }
+
+func TestParseCmdline(t *testing.T) {
+	cmdline := `BOOT_IMAGE=/vmlinuz-5.14.0 root=UUID=1234 ro quiet isolcpus=2-7 nohz_full=2-7 ` +
+		`default_hugepagesz=1G hugepagesz=1G hugepages=16 foo="a b" mitigations=off  -- single`
+
+	expected := map[string]string{
+		"BOOT_IMAGE":         "/vmlinuz-5.14.0",
+		"root":               "UUID=1234",
+		"ro":                 "true",
+		"quiet":              "true",
+		"isolcpus":           "2-7",
+		"nohz_full":          "2-7",
+		"default_hugepagesz": "1G",
+		"hugepagesz":         "1G",
+		"hugepages":          "16",
+		"foo":                "a b",
+		"mitigations":        "off",
+	}
+	assert.Equal(t, expected, parseCmdline(cmdline+"\n"))
+
+	assert.Empty(t, parseCmdline(""))
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package local
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+)
+
+// hookEnv is the (minimal) environment hooks are run with
+var hookEnv = []string{"PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"}
+
+// lastHookOutput contains the output of the latest successful run of each
+// hook. It is used in place of the output of a failed run.
+var lastHookOutput = make(map[string][]byte)
+
+// hookResult is the result of running one hook
+type hookResult struct {
+	output []byte
+	err    error
+}
+
+// Run all hooks and get features
+func getFeaturesFromHooks(c HooksConfig) (*feature.DomainFeatures, error) {
+	features := newFeatures()
+
+	files, err := ioutil.ReadDir(hookDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			klog.Infof("hook directory %v does not exist", hookDir)
+			return features, nil
+		}
+		return features, fmt.Errorf("unable to access %v: %v", hookDir, err)
+	}
+
+	results := runHooks(files, c)
+
+	seen := make(map[string]struct{}, len(files))
+	for i, file := range files {
+		fileName := file.Name()
+		seen[fileName] = struct{}{}
+
+		output, err := results[i].output, results[i].err
+		if err != nil {
+			klog.Errorf("source local failed running hook '%v': %v", fileName, err)
+			cached, ok := lastHookOutput[fileName]
+			if !ok {
+				continue
+			}
+			klog.Warningf("using output of the latest successful run of hook '%v'", fileName)
+			output = cached
+		} else {
+			lastHookOutput[fileName] = output
+		}
+
+		// Append features
+		fileFeatures, err := parseFeatureData(output)
+		if err != nil {
+			klog.Errorf("source local failed parsing output of hook '%v': %v", fileName, err)
+			continue
+		}
+		utils.KlogDump(4, fmt.Sprintf("features from hook %q:", fileName), "  ", fileFeatures)
+		mergeFeatures(features, fileFeatures, fmt.Sprintf("another hook (%s)", fileName))
+	}
+
+	// Drop cached output of removed hooks
+	for name := range lastHookOutput {
+		if _, ok := seen[name]; !ok {
+			delete(lastHookOutput, name)
+		}
+	}
+
+	return features, nil
+}
+
+// runHooks runs the hooks concurrently, returning the results in the same
+// order as the hooks were given.
+func runHooks(files []os.FileInfo, c HooksConfig) []hookResult {
+	results := make([]hookResult, len(files))
+
+	concurrency := c.Concurrency
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, name string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			results[i].output, results[i].err = runHook(name, c)
+		}(i, file.Name())
+	}
+	wg.Wait()
+
+	return results
+}
+
+// Run one hook
+func runHook(file string, c HooksConfig) ([]byte, error) {
+	path := filepath.Join(hookDir, file)
+	filestat, err := os.Stat(path)
+	if err != nil {
+		klog.Errorf("skipping %v, failed to get stat: %v", path, err)
+		return nil, err
+	}
+
+	if !filestat.Mode().IsRegular() {
+		return nil, nil
+	}
+
+	cmd := exec.Command(path)
+	stdout := &limitedBuffer{limit: c.MaxOutputSize}
+	stderr := &limitedBuffer{limit: c.MaxOutputSize}
+	cmd.Env = hookEnv
+	// Run in a separate process group so that all child processes of the
+	// hook can be killed on timeout
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	if c.UID != nil || c.GID != nil {
+		// Empty Groups drops all supplementary groups of the worker
+		cred := &syscall.Credential{Uid: uint32(os.Getuid()), Gid: uint32(os.Getgid()), Groups: []uint32{}}
+		if c.UID != nil {
+			cred.Uid = *c.UID
+		}
+		if c.GID != nil {
+			cred.Gid = *c.GID
+		}
+		cmd.SysProcAttr.Credential = cred
+	}
+
+	// Run hook
+	start := time.Now()
+	err = runWithTimeout(cmd, stdout, stderr, c.Timeout.Duration)
+	hookDuration.WithLabelValues(file).Observe(time.Since(start).Seconds())
+	hookExitCode.WithLabelValues(file).Set(float64(cmd.ProcessState.ExitCode()))
+
+	// Forward stderr to our logger
+	errLines := bytes.Split(stderr.Bytes(), []byte("\n"))
+	for i, line := range errLines {
+		if i == len(errLines)-1 && len(line) == 0 {
+			// Don't print the last empty string
+			break
+		}
+		klog.Errorf("%v: %s", file, line)
+	}
+
+	// Do not return any output if an error occurred
+	if err != nil {
+		return nil, err
+	}
+	if stdout.truncated {
+		return nil, fmt.Errorf("output exceeds the maximum size of %d bytes", c.MaxOutputSize)
+	}
+	return stdout.Bytes(), nil
+}
+
+// runWithTimeout runs a command, killing its process group if it does not
+// finish in time. Zero timeout means no timeout. Output is read through
+// separate pipes so that reading can be stopped on timeout even if some
+// process outside the process group keeps them open.
+func runWithTimeout(cmd *exec.Cmd, stdout, stderr io.Writer, timeout time.Duration) error {
+	outputs := []io.Writer{stdout, stderr}
+	readers := make([]*os.File, 0, len(outputs))
+	writers := make([]*os.File, 0, len(outputs))
+	defer func() {
+		for _, f := range append(readers, writers...) {
+			f.Close()
+		}
+	}()
+	for range outputs {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return err
+		}
+		readers = append(readers, r)
+		writers = append(writers, w)
+	}
+	cmd.Stdout = writers[0]
+	cmd.Stderr = writers[1]
+
+	err := cmd.Start()
+	// The child has its own copies of the write ends
+	for _, w := range writers {
+		w.Close()
+	}
+	writers = nil
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	for i := range readers {
+		wg.Add(1)
+		go func(w io.Writer, r io.Reader) {
+			defer wg.Done()
+			_, _ = io.Copy(w, r)
+		}(outputs[i], readers[i])
+	}
+	copied := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(copied)
+	}()
+	defer func() {
+		// Closing the read ends stops any reads still in progress
+		for _, r := range readers {
+			r.Close()
+		}
+		<-copied
+	}()
+
+	var expired <-chan time.Time
+	if timeout > 0 {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cmd.Wait() }()
+
+	select {
+	case err := <-done:
+		// Read the rest of the output, but stop on timeout if a left-over
+		// child process keeps the pipes open
+		select {
+		case <-copied:
+		case <-expired:
+			klog.Warningf("output of %v not closed in time, ignoring the rest of it", cmd.Path)
+		}
+		return err
+	case <-expired:
+		if err := syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL); err != nil {
+			klog.Errorf("failed to kill process group of %v: %v", cmd.Path, err)
+		}
+		// Output goes directly to the pipes so Wait does not block on them
+		<-done
+		return fmt.Errorf("timed out after %v", timeout)
+	}
+}
+
+// limitedBuffer is a buffer that silently discards all data after a size
+// limit has been reached. Non-positive limit means no limit.
+type limitedBuffer struct {
+	buf       bytes.Buffer
+	limit     int64
+	truncated bool
+}
+
+// Write implements the io.Writer interface
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	n := len(p)
+	if b.limit > 0 {
+		if room := b.limit - int64(b.buf.Len()); int64(n) > room {
+			b.truncated = true
+			if room <= 0 {
+				return n, nil
+			}
+			p = p[:room]
+		}
+	}
+	if _, err := b.buf.Write(p); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// Bytes returns the buffered data
+func (b *limitedBuffer) Bytes() []byte { return b.buf.Bytes() }
@@ -21,11 +21,13 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/klog/v2"
+	"sigs.k8s.io/yaml"
 
 	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
@@ -36,27 +38,111 @@ const Name = "local"
 
 const LabelFeature = "label"
 
+// ExpiryTimeDirective is the name of the feature file directive specifying
+// the time after which the file is ignored
+const ExpiryTimeDirective = "expiry-time"
+
 // Config
 var (
 	featureFilesDir = "/etc/kubernetes/node-feature-discovery/features.d/"
 	hookDir         = "/etc/kubernetes/node-feature-discovery/source.d/"
 )
 
-// localSource implements the FeatureSource and LabelSource interfaces.
+// Metrics
+var (
+	hookDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "nfd_worker_local_hook_duration_seconds",
+		Help:    "Time taken by running a hook of the local feature source.",
+		Buckets: []float64{0.01, 0.05, 0.1, 0.5, 1, 5, 10, 30, 60},
+	}, []string{"hook"})
+	hookExitCode = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "nfd_worker_local_hook_exit_code",
+		Help: "Exit code of the latest run of a hook of the local feature source. -1 if the hook could not be run.",
+	}, []string{"hook"})
+	expiredFeatureFiles = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "nfd_worker_local_expired_feature_files",
+		Help: "Number of expired (and thus ignored) feature files in the latest discovery round of the local feature source.",
+	})
+)
+
+// structuredFeatures is the structured (JSON or YAML) input format of feature
+// files and hooks. Each field maps feature names to the feature data.
+type structuredFeatures struct {
+	Labels     map[string]string              `json:"labels,omitempty"`
+	Flags      map[string][]string            `json:"flags,omitempty"`
+	Attributes map[string]map[string]string   `json:"attributes,omitempty"`
+	Instances  map[string][]map[string]string `json:"instances,omitempty"`
+}
+
+// Config holds the configuration parameters of this source.
+type Config struct {
+	Hooks HooksConfig `json:"hooks,omitempty"`
+}
+
+// HooksConfig holds the configuration parameters for running hooks.
+type HooksConfig struct {
+	// Timeout is the maximum time a hook is allowed to run
+	Timeout utils.DurationVal `json:"timeout,omitempty"`
+	// MaxOutputSize is the maximum size of stdout (and stderr) of a hook, in
+	// bytes
+	MaxOutputSize int64 `json:"maxOutputSize,omitempty"`
+	// Concurrency is the maximum number of hooks run in parallel
+	Concurrency int `json:"concurrency,omitempty"`
+	// UID and GID to run hooks as. Hooks are run with the credentials of
+	// nfd-worker if not specified.
+	UID *uint32 `json:"uid,omitempty"`
+	GID *uint32 `json:"gid,omitempty"`
+}
+
+// newDefaultConfig returns a new config with pre-populated defaults
+func newDefaultConfig() *Config {
+	return &Config{
+		Hooks: HooksConfig{
+			Timeout:       utils.DurationVal{Duration: 30 * time.Second},
+			MaxOutputSize: 1024 * 1024,
+			Concurrency:   4,
+		},
+	}
+}
+
+// localSource implements the FeatureSource, LabelSource and
+// ConfigurableSource interfaces.
 type localSource struct {
+	config   *Config
 	features *feature.DomainFeatures
 }
 
 // Singleton source instance
 var (
-	src localSource
-	_   source.FeatureSource = &src
-	_   source.LabelSource   = &src
+	src                           = localSource{config: newDefaultConfig()}
+	_   source.FeatureSource      = &src
+	_   source.LabelSource        = &src
+	_   source.ConfigurableSource = &src
+	_   source.WatchableSource    = &src
 )
 
 // Name method of the LabelSource interface
 func (s *localSource) Name() string { return Name }
 
+// WatchPaths method of the WatchableSource interface
+func (s *localSource) WatchPaths() []string { return []string{featureFilesDir, hookDir} }
+
+// NewConfig method of the LabelSource interface
+func (s *localSource) NewConfig() source.Config { return newDefaultConfig() }
+
+// GetConfig method of the LabelSource interface
+func (s *localSource) GetConfig() source.Config { return s.config }
+
+// SetConfig method of the LabelSource interface
+func (s *localSource) SetConfig(conf source.Config) {
+	switch v := conf.(type) {
+	case *Config:
+		s.config = v
+	default:
+		klog.Fatalf("invalid config type: %T", conf)
+	}
+}
+
 // Priority method of the LabelSource interface
 func (s *localSource) Priority() int { return 20 }
 
@@ -73,9 +159,7 @@ func (s *localSource) GetLabels() (source.FeatureLabels, error) {
 
 // Discover method of the FeatureSource interface
 func (s *localSource) Discover() error {
-	s.features = feature.NewDomainFeatures()
-
-	featuresFromHooks, err := getFeaturesFromHooks()
+	featuresFromHooks, err := getFeaturesFromHooks(s.config.Hooks)
 	if err != nil {
 		klog.Error(err)
 	}
@@ -86,14 +170,8 @@ func (s *localSource) Discover() error {
 	}
 
 	// Merge features from hooks and files
-	for k, v := range featuresFromHooks {
-		if old, ok := featuresFromFiles[k]; ok {
-			klog.Warningf("overriding '%s': value changed from '%s' to '%s'",
-				k, old, v)
-		}
-		featuresFromFiles[k] = v
-	}
-	s.features.Values[LabelFeature] = feature.NewValueFeatures(featuresFromFiles)
+	mergeFeatures(featuresFromFiles, featuresFromHooks, "hooks")
+	s.features = featuresFromFiles
 
 	utils.KlogDump(3, "discovered local features:", "  ", s.features)
 
@@ -108,11 +186,115 @@ func (s *localSource) GetFeatures() *feature.DomainFeatures {
 	return s.features
 }
 
+// newFeatures returns an empty set of features with an empty label feature
+func newFeatures() *feature.DomainFeatures {
+	f := feature.NewDomainFeatures()
+	f.Values[LabelFeature] = feature.NewValueFeatures(nil)
+	return f
+}
+
+// parseFeatureData parses the content of a feature file or the output of a
+// hook. The data is either in the structured (JSON or YAML) format or in the
+// plain key-value format.
+func parseFeatureData(data []byte) (*feature.DomainFeatures, error) {
+	if !isStructured(data) {
+		f := newFeatures()
+		f.Values[LabelFeature] = feature.NewValueFeatures(parseFeatures(bytes.Split(data, []byte("\n"))))
+		return f, nil
+	}
+
+	sf := structuredFeatures{}
+	if err := yaml.UnmarshalStrict(data, &sf); err != nil {
+		return nil, fmt.Errorf("failed to parse structured features: %w", err)
+	}
+
+	f := newFeatures()
+	for k, v := range sf.Labels {
+		f.Values[LabelFeature].Elements[k] = v
+	}
+	for name, v := range sf.Flags {
+		if name == LabelFeature {
+			return nil, fmt.Errorf("invalid feature name %q: reserved for labels", name)
+		}
+		f.Keys[name] = feature.NewKeyFeatures(v...)
+	}
+	for name, v := range sf.Attributes {
+		if name == LabelFeature {
+			return nil, fmt.Errorf("invalid feature name %q: reserved for labels", name)
+		}
+		f.Values[name] = feature.NewValueFeatures(v)
+	}
+	for name, v := range sf.Instances {
+		if name == LabelFeature {
+			return nil, fmt.Errorf("invalid feature name %q: reserved for labels", name)
+		}
+		instances := make([]feature.InstanceFeature, len(v))
+		for i, attrs := range v {
+			instances[i] = *feature.NewInstanceFeature(attrs)
+		}
+		f.Instances[name] = feature.NewInstanceFeatures(instances)
+	}
+
+	return f, nil
+}
+
+// isStructured returns true if the data is a JSON or YAML document (as
+// opposed to plain key-value pairs) whose top-level keys are all known
+// fields of the structured format.
+func isStructured(data []byte) bool {
+	raw := map[string]interface{}{}
+	if err := yaml.Unmarshal(data, &raw); err != nil || len(raw) == 0 {
+		return false
+	}
+	for k := range raw {
+		switch k {
+		case "labels", "flags", "attributes", "instances":
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// mergeFeatures merges features from src into dst. Labels are merged one by
+// one whereas other features are replaced as a whole.
+func mergeFeatures(dst, src *feature.DomainFeatures, origin string) {
+	for k, v := range src.Values[LabelFeature].Elements {
+		if old, ok := dst.Values[LabelFeature].Elements[k]; ok {
+			klog.Warningf("overriding label '%s' from %s: value changed from '%s' to '%s'",
+				k, origin, old, v)
+		}
+		dst.Values[LabelFeature].Elements[k] = v
+	}
+	for name, v := range src.Keys {
+		if _, ok := dst.Keys[name]; ok {
+			klog.Warningf("overriding feature '%s' from %s", name, origin)
+		}
+		dst.Keys[name] = v
+	}
+	for name, v := range src.Values {
+		if name == LabelFeature {
+			continue
+		}
+		if _, ok := dst.Values[name]; ok {
+			klog.Warningf("overriding feature '%s' from %s", name, origin)
+		}
+		dst.Values[name] = v
+	}
+	for name, v := range src.Instances {
+		if _, ok := dst.Instances[name]; ok {
+			klog.Warningf("overriding feature '%s' from %s", name, origin)
+		}
+		dst.Instances[name] = v
+	}
+}
+
 func parseFeatures(lines [][]byte) map[string]string {
 	features := make(map[string]string)
 
 	for _, line := range lines {
-		if len(line) > 0 {
+		// Skip empty lines and comments
+		if len(line) > 0 && line[0] != '#' {
 			lineSplit := strings.SplitN(string(line), "=", 2)
 
 			key := lineSplit[0]
@@ -129,143 +311,114 @@ func parseFeatures(lines [][]byte) map[string]string {
 	return features
 }
 
-// Run all hooks and get features
-func getFeaturesFromHooks() (map[string]string, error) {
-	features := make(map[string]string)
+// Read all files to get features
+func getFeaturesFromFiles() (*feature.DomainFeatures, error) {
+	features := newFeatures()
 
-	files, err := ioutil.ReadDir(hookDir)
+	files, err := ioutil.ReadDir(featureFilesDir)
 	if err != nil {
 		if os.IsNotExist(err) {
-			klog.Infof("hook directory %v does not exist", hookDir)
+			klog.Infof("features directory %v does not exist", featureFilesDir)
 			return features, nil
 		}
-		return features, fmt.Errorf("unable to access %v: %v", hookDir, err)
+		return features, fmt.Errorf("unable to access %v: %v", featureFilesDir, err)
 	}
 
+	expired := 0
 	for _, file := range files {
 		fileName := file.Name()
-		lines, err := runHook(fileName)
+		content, err := getFileContent(fileName)
 		if err != nil {
-			klog.Errorf("source local failed running hook '%v': %v", fileName, err)
+			klog.Errorf("source local failed reading file '%v': %v", fileName, err)
 			continue
 		}
 
-		// Append features
-		fileFeatures := parseFeatures(lines)
-		utils.KlogDump(4, fmt.Sprintf("features from hook %q:", fileName), "  ", fileFeatures)
-		for k, v := range fileFeatures {
-			if old, ok := features[k]; ok {
-				klog.Warningf("overriding label '%s' from another hook (%s): value changed from '%s' to '%s'",
-					k, fileName, old, v)
-			}
-			features[k] = v
+		// Handle directives
+		directives, err := parseDirectives(content)
+		if err != nil {
+			klog.Errorf("source local failed parsing directives of file '%v': %v", fileName, err)
+			continue
 		}
-	}
-
-	return features, nil
-}
-
-// Run one hook
-func runHook(file string) ([][]byte, error) {
-	var lines [][]byte
-
-	path := filepath.Join(hookDir, file)
-	filestat, err := os.Stat(path)
-	if err != nil {
-		klog.Errorf("skipping %v, failed to get stat: %v", path, err)
-		return lines, err
-	}
-
-	if filestat.Mode().IsRegular() {
-		cmd := exec.Command(path)
-		var stdout bytes.Buffer
-		var stderr bytes.Buffer
-		cmd.Stdout = &stdout
-		cmd.Stderr = &stderr
-
-		// Run hook
-		err = cmd.Run()
-
-		// Forward stderr to our logger
-		errLines := bytes.Split(stderr.Bytes(), []byte("\n"))
-		for i, line := range errLines {
-			if i == len(errLines)-1 && len(line) == 0 {
-				// Don't print the last empty string
-				break
+		if expiryTime, ok := directives[ExpiryTimeDirective]; ok {
+			t, err := time.Parse(time.RFC3339, expiryTime)
+			if err != nil {
+				klog.Errorf("source local failed parsing expiry time of file '%v': %v", fileName, err)
+				continue
+			}
+			if time.Now().After(t) {
+				klog.Warningf("ignoring expired feature file '%v' (expired at %v)", fileName, t)
+				expired++
+				continue
 			}
-			klog.Errorf("%v: %s", file, line)
 		}
 
-		// Do not return any lines if an error occurred
+		// Append features
+		fileFeatures, err := parseFeatureData(content)
 		if err != nil {
-			return lines, err
+			klog.Errorf("source local failed parsing file '%v': %v", fileName, err)
+			continue
 		}
-		lines = bytes.Split(stdout.Bytes(), []byte("\n"))
+		utils.KlogDump(4, fmt.Sprintf("features from feature file %q:", fileName), "  ", fileFeatures)
+		mergeFeatures(features, fileFeatures, fmt.Sprintf("another features.d file (%s)", fileName))
 	}
+	expiredFeatureFiles.Set(float64(expired))
 
-	return lines, nil
+	return features, nil
 }
 
-// Read all files to get features
-func getFeaturesFromFiles() (map[string]string, error) {
-	features := make(map[string]string)
+// parseDirectives parses the directives in the header of a feature file. The
+// header consists of the comment lines at the beginning of the file.
+// Directives are comment lines of the form "# +<name>=<value>".
+func parseDirectives(data []byte) (map[string]string, error) {
+	directives := make(map[string]string)
 
-	files, err := ioutil.ReadDir(featureFilesDir)
-	if err != nil {
-		if os.IsNotExist(err) {
-			klog.Infof("features directory %v does not exist", featureFilesDir)
-			return features, nil
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		line = bytes.TrimSpace(line)
+		if len(line) == 0 {
+			continue
+		}
+		if line[0] != '#' {
+			// End of header
+			break
 		}
-		return features, fmt.Errorf("unable to access %v: %v", featureFilesDir, err)
-	}
 
-	for _, file := range files {
-		fileName := file.Name()
-		lines, err := getFileContent(fileName)
-		if err != nil {
-			klog.Errorf("source local failed reading file '%v': %v", fileName, err)
+		d := strings.TrimSpace(string(line[1:]))
+		if !strings.HasPrefix(d, "+") {
 			continue
 		}
-
-		// Append features
-		fileFeatures := parseFeatures(lines)
-		utils.KlogDump(4, fmt.Sprintf("features from feature file %q:", fileName), "  ", fileFeatures)
-		for k, v := range fileFeatures {
-			if old, ok := features[k]; ok {
-				klog.Warningf("overriding label '%s' from another features.d file (%s): value changed from '%s' to '%s'",
-					k, fileName, old, v)
-			}
-			features[k] = v
+		split := strings.SplitN(d[1:], "=", 2)
+		if len(split) != 2 {
+			return nil, fmt.Errorf("invalid directive %q: value missing", d)
+		}
+		switch split[0] {
+		case ExpiryTimeDirective:
+			directives[split[0]] = split[1]
+		default:
+			klog.Warningf("ignoring unknown directive %q", d)
 		}
 	}
 
-	return features, nil
+	return directives, nil
 }
 
 // Read one file
-func getFileContent(fileName string) ([][]byte, error) {
-	var lines [][]byte
-
+func getFileContent(fileName string) ([]byte, error) {
 	path := filepath.Join(featureFilesDir, fileName)
 	filestat, err := os.Stat(path)
 	if err != nil {
 		klog.Errorf("skipping %v, failed to get stat: %v", path, err)
-		return lines, err
+		return nil, err
 	}
 
 	if filestat.Mode().IsRegular() {
-		fileContent, err := ioutil.ReadFile(path)
-
-		// Do not return any lines if an error occurred
-		if err != nil {
-			return lines, err
-		}
-		lines = bytes.Split(fileContent, []byte("\n"))
+		return ioutil.ReadFile(path)
 	}
 
-	return lines, nil
+	return nil, nil
 }
 
 func init() {
 	source.Register(&src)
+
+	prometheus.MustRegister(hookDuration, hookExitCode, expiredFeatureFiles)
 }
@@ -17,9 +17,17 @@ limitations under the License.
 package local
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
 )
 
 func TestLocalSource(t *testing.T) {
@@ -33,3 +41,127 @@ func TestLocalSource(t *testing.T) {
 	assert.Empty(t, l)
 
 }
+
+func TestParseFeatureData(t *testing.T) {
+	// Plain key-value format
+	f, err := parseFeatureData([]byte("feature-1\nfeature-2=val-2\n"))
+	assert.Nil(t, err, err)
+	assert.Equal(t, map[string]string{"feature-1": "true", "feature-2": "val-2"}, f.Values[LabelFeature].Elements)
+	assert.Empty(t, f.Keys)
+
+	// Structured format
+	data := `
+labels:
+  feature-1: "true"
+flags:
+  my-flags: [flag-1, flag-2]
+attributes:
+  my-attrs:
+    attr-1: "1"
+instances:
+  my-devices:
+    - name: dev-0
+      vendor: "8086"
+`
+	f, err = parseFeatureData([]byte(data))
+	assert.Nil(t, err, err)
+	assert.Equal(t, map[string]string{"feature-1": "true"}, f.Values[LabelFeature].Elements)
+	assert.Equal(t, feature.NewKeyFeatures("flag-1", "flag-2"), f.Keys["my-flags"])
+	assert.Equal(t, feature.NewValueFeatures(map[string]string{"attr-1": "1"}), f.Values["my-attrs"])
+	assert.Equal(t, []feature.InstanceFeature{*feature.NewInstanceFeature(map[string]string{"name": "dev-0", "vendor": "8086"})},
+		f.Instances["my-devices"].Elements)
+
+	// JSON is also accepted
+	f, err = parseFeatureData([]byte(`{"flags": {"my-flags": ["flag-1"]}}`))
+	assert.Nil(t, err, err)
+	assert.Equal(t, feature.NewKeyFeatures("flag-1"), f.Keys["my-flags"])
+
+	// Invalid structured data
+	_, err = parseFeatureData([]byte(`{"flags": {"label": ["flag-1"]}}`))
+	assert.NotNil(t, err)
+	_, err = parseFeatureData([]byte(`{"flags": {"my-flags": {"a": "b"}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestHooks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfd-local-test")
+	assert.Nil(t, err, err)
+	defer os.RemoveAll(dir)
+
+	origHookDir := hookDir
+	hookDir = dir
+	defer func() { hookDir = origHookDir }()
+
+	writeHook := func(name, script string) {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte("#!/bin/sh\n"+script+"\n"), 0755)
+		assert.Nil(t, err, err)
+	}
+	writeHook("hook-1", "echo feature-1")
+	// The background process escapes the process group but keeps stdout open
+	writeHook("hook-2", "setsid sleep 10 & sleep 10; echo feature-2")
+	writeHook("hook-3", "echo feature-3=0123456789")
+
+	c := HooksConfig{
+		Timeout:       utils.DurationVal{Duration: 500 * time.Millisecond},
+		MaxOutputSize: 16,
+		Concurrency:   2,
+	}
+
+	// Hooks that time out or produce too much output are ignored
+	start := time.Now()
+	f, err := getFeaturesFromHooks(c)
+	assert.Nil(t, err, err)
+	assert.Less(t, time.Since(start).Seconds(), 5.0)
+	assert.Equal(t, map[string]string{"feature-1": "true"}, f.Values[LabelFeature].Elements)
+
+	// Output of the last successful run is used if a hook fails
+	writeHook("hook-1", "exit 1")
+	f, err = getFeaturesFromHooks(c)
+	assert.Nil(t, err, err)
+	assert.Equal(t, map[string]string{"feature-1": "true"}, f.Values[LabelFeature].Elements)
+
+	// Hooks are run with a minimal environment
+	os.Setenv("NFD_TEST_VAR", "foo")
+	defer os.Unsetenv("NFD_TEST_VAR")
+	writeHook("hook-1", "echo env=${NFD_TEST_VAR:-unset}")
+	f, err = getFeaturesFromHooks(c)
+	assert.Nil(t, err, err)
+	assert.Equal(t, map[string]string{"env": "unset"}, f.Values[LabelFeature].Elements)
+
+	// Hooks run as the configured user don't inherit supplementary groups
+	if os.Getuid() == 0 {
+		assert.Nil(t, os.Chmod(dir, 0755))
+		writeHook("hook-1", "echo groups=$(id -G | tr ' ' '_')")
+		uid, gid := uint32(65534), uint32(65534)
+		c.UID, c.GID = &uid, &gid
+		f, err = getFeaturesFromHooks(c)
+		assert.Nil(t, err, err)
+		assert.Equal(t, map[string]string{"groups": "65534"}, f.Values[LabelFeature].Elements)
+	}
+}
+
+func TestFeatureFileExpiry(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfd-local-test")
+	assert.Nil(t, err, err)
+	defer os.RemoveAll(dir)
+
+	origFeatureFilesDir := featureFilesDir
+	featureFilesDir = dir
+	defer func() { featureFilesDir = origFeatureFilesDir }()
+
+	writeFile := func(name, content string) {
+		err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+		assert.Nil(t, err, err)
+	}
+	past := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	future := time.Now().Add(time.Hour).Format(time.RFC3339)
+	writeFile("expired", "# +expiry-time="+past+"\nfeature-1\n")
+	writeFile("valid", "# Some comment\n# +expiry-time="+future+"\nfeature-2\n")
+	writeFile("no-expiry", "feature-3\n")
+	writeFile("invalid", "# +expiry-time=tomorrow\nfeature-4\n")
+
+	f, err := getFeaturesFromFiles()
+	assert.Nil(t, err, err)
+	assert.Equal(t, map[string]string{"feature-2": "true", "feature-3": "true"}, f.Values[LabelFeature].Elements)
+	assert.Equal(t, 1.0, testutil.ToFloat64(expiredFeatureFiles))
+}
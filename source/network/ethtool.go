@@ -0,0 +1,160 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package network
+
+import (
+	"bytes"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	siocEthtool      = 0x8946
+	ethtoolGSStrings = 0x1b
+	ethtoolGSSetInfo = 0x37
+	ethtoolGFeatures = 0x3a
+	ethSSFeatures    = 4
+	ethGStringLen    = 32
+	ifNameSize       = 16
+	featureBlockBits = 32
+)
+
+// offloadFeatures are the hardware offload features (as named by ethtool)
+// published as network device attributes
+var offloadFeatures = []string{
+	"esp-hw-offload",
+	"hw-tc-offload",
+	"l2-fwd-offload",
+	"macsec-hw-offload",
+	"rx-checksum",
+	"rx-gro-hw",
+	"rx-lro",
+	"rx-ntuple-filter",
+	"rx-vlan-hw-parse",
+	"tls-hw-rx-offload",
+	"tls-hw-tx-offload",
+	"tx-checksum-ip-generic",
+	"tx-tcp-segmentation",
+	"tx-udp_tnl-segmentation",
+	"tx-vlan-hw-insert",
+}
+
+// ifreq is the ioctl request structure used with SIOCETHTOOL
+type ifreq struct {
+	name [ifNameSize]byte
+	data unsafe.Pointer
+	_    [16]byte
+}
+
+// ethtoolSsetInfo is struct ethtool_sset_info with room for one string set
+type ethtoolSsetInfo struct {
+	cmd      uint32
+	reserved uint32
+	ssetMask uint64
+	data     uint32
+}
+
+// ethtoolGstrings is the header of struct ethtool_gstrings
+type ethtoolGstrings struct {
+	cmd       uint32
+	stringSet uint32
+	len       uint32
+}
+
+// ethtoolIoctl runs one SIOCETHTOOL request for an interface, data pointing
+// to the ethtool command structure.
+func ethtoolIoctl(fd int, iface string, data unsafe.Pointer) error {
+	req := ifreq{data: data}
+	copy(req.name[:ifNameSize-1], iface)
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), siocEthtool, uintptr(unsafe.Pointer(&req)))
+	if errno != 0 {
+		return errno
+	}
+	return nil
+}
+
+// offloadState is the state of one hardware offload feature
+type offloadState struct {
+	// supported is true if the device is capable of the feature, i.e. it can
+	// be enabled or is always on
+	supported bool
+	// active is true if the feature is currently enabled
+	active bool
+}
+
+// getOffloadFeatures returns the state of the hardware offload features of a
+// network interface. The interface must be visible in the network namespace
+// of nfd-worker.
+func getOffloadFeatures(iface string) (map[string]offloadState, error) {
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer syscall.Close(fd)
+
+	// Number of features
+	info := ethtoolSsetInfo{cmd: ethtoolGSSetInfo, ssetMask: 1 << ethSSFeatures}
+	if err := ethtoolIoctl(fd, iface, unsafe.Pointer(&info)); err != nil {
+		return nil, fmt.Errorf("ETHTOOL_GSSET_INFO failed: %w", err)
+	}
+	if info.ssetMask == 0 {
+		return nil, fmt.Errorf("feature names not available")
+	}
+	n := int(info.data)
+
+	// Feature names
+	hdrSize := int(unsafe.Sizeof(ethtoolGstrings{}))
+	buf := make([]byte, hdrSize+n*ethGStringLen)
+	*(*ethtoolGstrings)(unsafe.Pointer(&buf[0])) = ethtoolGstrings{cmd: ethtoolGSStrings, stringSet: ethSSFeatures, len: uint32(n)}
+	if err := ethtoolIoctl(fd, iface, unsafe.Pointer(&buf[0])); err != nil {
+		return nil, fmt.Errorf("ETHTOOL_GSTRINGS failed: %w", err)
+	}
+	names := make(map[string]int, n)
+	for i := 0; i < n; i++ {
+		s := buf[hdrSize+i*ethGStringLen : hdrSize+(i+1)*ethGStringLen]
+		if end := bytes.IndexByte(s, 0); end >= 0 {
+			s = s[:end]
+		}
+		names[string(s)] = i
+	}
+
+	// Feature states, struct ethtool_gfeatures consists of the cmd and size
+	// fields followed by blocks of available, requested, active and
+	// never_changed bitmaps
+	blocks := (n + featureBlockBits - 1) / featureBlockBits
+	gfeatures := make([]uint32, 2+blocks*4)
+	gfeatures[0], gfeatures[1] = ethtoolGFeatures, uint32(blocks)
+	if err := ethtoolIoctl(fd, iface, unsafe.Pointer(&gfeatures[0])); err != nil {
+		return nil, fmt.Errorf("ETHTOOL_GFEATURES failed: %w", err)
+	}
+
+	features := make(map[string]offloadState, len(offloadFeatures))
+	for _, name := range offloadFeatures {
+		i, ok := names[name]
+		if !ok {
+			continue
+		}
+		block := gfeatures[2+(i/featureBlockBits)*4:]
+		bit := uint32(1) << (i % featureBlockBits)
+		// Features that are fixed on are not reported as available
+		available, active := block[0]&bit != 0, block[2]&bit != 0
+		features[name] = offloadState{supported: available || active, active: active}
+	}
+	return features, nil
+}
@@ -17,12 +17,15 @@ limitations under the License.
 package network
 
 import (
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"net"
 	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"syscall"
 
 	"k8s.io/klog/v2"
 
@@ -37,28 +40,78 @@ const DeviceFeature = "device"
 
 const sysfsBaseDir = "class/net"
 
-// networkSource implements the FeatureSource and LabelSource interfaces.
+// Config holds the configuration parameters of this source.
+type Config struct {
+	// LinkSpeedThresholds is the list of link speeds (in Gbps) for which to
+	// create labels
+	LinkSpeedThresholds []int `json:"linkSpeedThresholds,omitempty"`
+}
+
+// newDefaultConfig returns a new config with pre-populated defaults
+func newDefaultConfig() *Config {
+	return &Config{
+		LinkSpeedThresholds: []int{10, 25, 40, 100},
+	}
+}
+
+// networkSource implements the FeatureSource, LabelSource,
+// ConfigurableSource and ValidatingSource interfaces.
 type networkSource struct {
+	config   *Config
 	features *feature.DomainFeatures
 }
 
 // Singleton source instance
 var (
-	src networkSource
-	_   source.FeatureSource = &src
-	_   source.LabelSource   = &src
+	src                           = networkSource{config: newDefaultConfig()}
+	_   source.FeatureSource      = &src
+	_   source.LabelSource        = &src
+	_   source.ConfigurableSource = &src
+	_   source.ValidatingSource   = &src
 )
 
 var (
 	// ifaceAttrs is the list of files under /sys/class/net/<iface> that we're trying to read
-	ifaceAttrs = []string{"operstate", "speed"}
+	ifaceAttrs = []string{"operstate", "speed", "mtu", "phys_switch_id", "phys_port_name"}
 	// devAttrs is the list of files under /sys/class/net/<iface>/device that we're trying to read
-	devAttrs = []string{"sriov_numvfs", "sriov_totalvfs"}
+	devAttrs = []string{"sriov_numvfs", "sriov_totalvfs", "numa_node"}
 )
 
 // Name returns an identifier string for this feature source.
 func (s *networkSource) Name() string { return Name }
 
+// NewConfig method of the LabelSource interface
+func (s *networkSource) NewConfig() source.Config { return newDefaultConfig() }
+
+// GetConfig method of the LabelSource interface
+func (s *networkSource) GetConfig() source.Config { return s.config }
+
+// SetConfig method of the LabelSource interface
+func (s *networkSource) SetConfig(conf source.Config) {
+	switch v := conf.(type) {
+	case *Config:
+		s.config = v
+	default:
+		klog.Fatalf("invalid config type: %T", conf)
+	}
+}
+
+// ValidateConfig method of the ValidatingSource interface
+func (s *networkSource) ValidateConfig(conf source.Config) []error {
+	c, ok := conf.(*Config)
+	if !ok {
+		return []error{fmt.Errorf("invalid config type: %T", conf)}
+	}
+
+	errs := []error{}
+	for _, t := range c.LinkSpeedThresholds {
+		if t <= 0 {
+			errs = append(errs, fmt.Errorf("invalid link speed threshold %d in linkSpeedThresholds", t))
+		}
+	}
+	return errs
+}
+
 // Priority method of the LabelSource interface
 func (s *networkSource) Priority() int { return 0 }
 
@@ -67,11 +120,16 @@ func (s *networkSource) GetLabels() (source.FeatureLabels, error) {
 	labels := source.FeatureLabels{}
 	features := s.GetFeatures()
 
+	maxSpeed := 0
 	for _, dev := range features.Instances[DeviceFeature].Elements {
 		attrs := dev.Attributes
 		if attrs["operstate"] != "up" {
 			continue
 		}
+		// Speed is reported in Mbps, -1 (or no value at all) if unknown
+		if speed, err := strconv.Atoi(attrs["speed"]); err == nil && speed/1000 > maxSpeed {
+			maxSpeed = speed / 1000
+		}
 		for attr, feature := range map[string]string{
 			"sriov_totalvfs": "sriov.capable",
 			"sriov_numvfs":   "sriov.configured"} {
@@ -88,6 +146,15 @@ func (s *networkSource) GetLabels() (source.FeatureLabels, error) {
 			}
 		}
 	}
+
+	for _, t := range s.config.LinkSpeedThresholds {
+		if t > 0 && maxSpeed >= t {
+			labels[fmt.Sprintf("link.%dgbps", t)] = true
+		}
+	}
+	if maxSpeed > 0 {
+		labels["link.max_speed"] = maxSpeed
+	}
 	return labels, nil
 }
 
@@ -137,22 +204,25 @@ func detectNetDevices() ([]feature.InstanceFeature, error) {
 }
 
 func readIfaceInfo(path string) feature.InstanceFeature {
-	attrs := map[string]string{"name": filepath.Base(path)}
+	name := filepath.Base(path)
+	attrs := map[string]string{"name": name}
 	for _, attrName := range ifaceAttrs {
 		data, err := ioutil.ReadFile(filepath.Join(path, attrName))
 		if err != nil {
-			if !os.IsNotExist(err) {
+			if !isUnsupported(err) {
 				klog.Errorf("failed to read net iface attribute %s: %v", attrName, err)
 			}
 			continue
 		}
-		attrs[attrName] = strings.TrimSpace(string(data))
+		if v := strings.TrimSpace(string(data)); v != "" {
+			attrs[attrName] = v
+		}
 	}
 
 	for _, attrName := range devAttrs {
 		data, err := ioutil.ReadFile(filepath.Join(path, "device", attrName))
 		if err != nil {
-			if !os.IsNotExist(err) {
+			if !isUnsupported(err) {
 				klog.Errorf("failed to read net device attribute %s: %v", attrName, err)
 			}
 			continue
@@ -160,8 +230,59 @@ func readIfaceInfo(path string) feature.InstanceFeature {
 		attrs[attrName] = strings.TrimSpace(string(data))
 	}
 
+	if target, err := os.Readlink(filepath.Join(path, "device", "driver")); err == nil {
+		attrs["driver"] = filepath.Base(target)
+	}
+	if target, err := os.Readlink(filepath.Join(path, "device", "subsystem")); err == nil && filepath.Base(target) == "pci" {
+		if realPath, err := filepath.EvalSymlinks(filepath.Join(path, "device")); err == nil {
+			attrs["pci_address"] = filepath.Base(realPath)
+		}
+	}
+
+	// Bond or bridge the interface is enslaved to
+	if target, err := os.Readlink(filepath.Join(path, "master")); err == nil {
+		attrs["master"] = filepath.Base(target)
+		for _, t := range []string{"bonding", "bridge"} {
+			if _, err := os.Stat(filepath.Join(path, "master", t)); err == nil {
+				attrs["master_type"] = t
+			}
+		}
+	}
+
+	if !inCurrentNetns(path) {
+		klog.V(3).Infof("iface %q not in the network namespace of nfd-worker, not getting offload features", name)
+	} else if offloads, err := getOffloadFeatures(name); err != nil {
+		klog.V(3).Infof("failed to get offload features of %s: %v", name, err)
+	} else {
+		for k, v := range offloads {
+			attrs[k] = strconv.FormatBool(v.supported)
+			attrs[k+"_active"] = strconv.FormatBool(v.active)
+		}
+	}
+
 	return *feature.NewInstanceFeature(attrs)
+}
+
+// inCurrentNetns checks if a network interface in sysfs is also visible in the
+// network namespace of the running process, i.e. that we are running in the
+// host network namespace (or the sysfs corresponds to our own namespace).
+func inCurrentNetns(path string) bool {
+	iface, err := net.InterfaceByName(filepath.Base(path))
+	if err != nil {
+		return false
+	}
+	ifindex, err := ioutil.ReadFile(filepath.Join(path, "ifindex"))
+	if err != nil || strings.TrimSpace(string(ifindex)) != strconv.Itoa(iface.Index) {
+		return false
+	}
+	address, err := ioutil.ReadFile(filepath.Join(path, "address"))
+	return err == nil && strings.TrimSpace(string(address)) == iface.HardwareAddr.String()
+}
 
+// isUnsupported returns true if an error indicates that a sysfs attribute is
+// not available for the device.
+func isUnsupported(err error) bool {
+	return os.IsNotExist(err) || errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, syscall.EINVAL)
 }
 
 func init() {
@@ -17,9 +17,15 @@ limitations under the License.
 package network
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/source"
+	"sigs.k8s.io/node-feature-discovery/source/testutils"
 )
 
 func TestNetworkSource(t *testing.T) {
@@ -33,3 +39,80 @@ func TestNetworkSource(t *testing.T) {
 	assert.Empty(t, l)
 
 }
+
+func TestDetectNetDevices(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+	symlink := func(target, name string) {
+		assert.Nil(t, os.MkdirAll(filepath.Join(sysfs, filepath.Dir(name)), 0755))
+		assert.Nil(t, os.Symlink(target, filepath.Join(sysfs, name)))
+	}
+
+	pciDev := "devices/pci0000:00/0000:00:02.0/0000:3b:00.0"
+	testutils.WriteFiles(t, filepath.Join(sysfs, pciDev), map[string]string{
+		"numa_node":      "1",
+		"sriov_totalvfs": "64",
+		"sriov_numvfs":   "0",
+	})
+	testutils.WriteFiles(t, filepath.Join(sysfs, pciDev+"/net/nfdtest0"), map[string]string{
+		"operstate":      "up",
+		"speed":          "25000",
+		"mtu":            "9000",
+		"phys_switch_id": "a0b1c2d3",
+		"phys_port_name": "p0",
+		"ifindex":        "1000",
+	})
+	symlink("../../../bus/pci/drivers/mlx5_core", pciDev+"/driver")
+	symlink("../../../bus/pci", pciDev+"/subsystem")
+	symlink("../..", pciDev+"/net/nfdtest0/device")
+	symlink(filepath.Join(sysfs, "devices/virtual/net/bond0"), pciDev+"/net/nfdtest0/master")
+	testutils.WriteFiles(t, filepath.Join(sysfs, "devices/virtual/net/bond0"), map[string]string{"bonding/mode": "802.3ad 4"})
+	symlink("../../"+pciDev+"/net/nfdtest0", "class/net/nfdtest0")
+	symlink("../../devices/virtual/net/bond0", "class/net/bond0")
+
+	devs, err := detectNetDevices()
+	assert.Nil(t, err, err)
+
+	expected := []feature.InstanceFeature{
+		*feature.NewInstanceFeature(map[string]string{
+			"name":           "nfdtest0",
+			"operstate":      "up",
+			"speed":          "25000",
+			"mtu":            "9000",
+			"phys_switch_id": "a0b1c2d3",
+			"phys_port_name": "p0",
+			"numa_node":      "1",
+			"sriov_totalvfs": "64",
+			"sriov_numvfs":   "0",
+			"driver":         "mlx5_core",
+			"pci_address":    "0000:3b:00.0",
+			"master":         "bond0",
+			"master_type":    "bonding",
+		}),
+	}
+	assert.Equal(t, expected, devs)
+}
+
+func TestNetworkLabels(t *testing.T) {
+	origConfig, origFeatures := src.config, src.features
+	defer func() { src.config, src.features = origConfig, origFeatures }()
+
+	src.config = newDefaultConfig()
+	src.features = feature.NewDomainFeatures()
+	src.features.Instances[DeviceFeature] = feature.NewInstanceFeatures([]feature.InstanceFeature{
+		*feature.NewInstanceFeature(map[string]string{"name": "eth0", "operstate": "up", "speed": "25000", "sriov_totalvfs": "8"}),
+		*feature.NewInstanceFeature(map[string]string{"name": "eth1", "operstate": "down", "speed": "100000"}),
+		*feature.NewInstanceFeature(map[string]string{"name": "eth2", "operstate": "up", "speed": "-1"}),
+	})
+
+	l, err := src.GetLabels()
+	assert.Nil(t, err, err)
+	assert.Equal(t, source.FeatureLabels{
+		"sriov.capable":  true,
+		"link.10gbps":    true,
+		"link.25gbps":    true,
+		"link.max_speed": 25,
+	}, l)
+
+	errs := src.ValidateConfig(&Config{LinkSpeedThresholds: []int{10, 0, -1}})
+	assert.Len(t, errs, 2)
+}
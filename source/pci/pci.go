@@ -23,6 +23,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/hwids"
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
 	"sigs.k8s.io/node-feature-discovery/source"
 )
@@ -31,9 +32,19 @@ const Name = "pci"
 
 const DeviceFeature = "device"
 
+// maxDevLabelLen is the maximum length of the device part of label names that
+// keeps the label names within the 63 character limit
+const maxDevLabelLen = 63 - len(Name+"-") - len(".sriov.capable")
+
 type Config struct {
 	DeviceClassWhitelist []string `json:"deviceClassWhitelist,omitempty"`
 	DeviceLabelFields    []string `json:"deviceLabelFields,omitempty"`
+	// ResolveNames enables resolving of vendor, device and class names from
+	// the pci.ids database
+	ResolveNames bool `json:"resolveNames,omitempty"`
+	// IDsFile is the path to the pci.ids database, overriding the default
+	// search paths
+	IDsFile string `json:"idsFile,omitempty"`
 }
 
 // newDefaultConfig returns a new config with pre-populated defaults
@@ -92,7 +103,7 @@ func (s *pciSource) GetLabels() (source.FeatureLabels, error) {
 		configLabelFields[field] = struct{}{}
 	}
 
-	for _, attr := range mandatoryDevAttrs {
+	for _, attr := range append(mandatoryDevAttrs, hwids.NameAttrs...) {
 		if _, ok := configLabelFields[attr]; ok {
 			deviceLabelFields = append(deviceLabelFields, attr)
 			delete(configLabelFields, attr)
@@ -116,13 +127,7 @@ func (s *pciSource) GetLabels() (source.FeatureLabels, error) {
 		class := attrs["class"]
 		for _, white := range s.config.DeviceClassWhitelist {
 			if strings.HasPrefix(string(class), strings.ToLower(white)) {
-				devLabel := ""
-				for i, attr := range deviceLabelFields {
-					devLabel += attrs[attr]
-					if i < len(deviceLabelFields)-1 {
-						devLabel += "_"
-					}
-				}
+				devLabel := hwids.DeviceLabel(attrs, deviceLabelFields, maxDevLabelLen)
 				labels[devLabel+".present"] = true
 
 				if _, ok := attrs["sriov_totalvfs"]; ok {
@@ -143,6 +148,11 @@ func (s *pciSource) Discover() error {
 	if err != nil {
 		return fmt.Errorf("failed to detect PCI devices: %s", err.Error())
 	}
+	if s.config.ResolveNames {
+		if err := hwids.ResolveNames(devs, hwids.FilePaths(s.config.IDsFile, source.UsrDir.Path(), "pci.ids")); err != nil {
+			klog.Warningf("failed to resolve PCI device names: %v", err)
+		}
+	}
 	s.features.Instances[DeviceFeature] = feature.NewInstanceFeatures(devs)
 
 	utils.KlogDump(3, "discovered pci features:", "  ", s.features)
@@ -17,9 +17,18 @@ limitations under the License.
 package pci
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/hwids"
+	"sigs.k8s.io/node-feature-discovery/source"
+	"sigs.k8s.io/node-feature-discovery/source/testutils"
 )
 
 func TestPciSource(t *testing.T) {
@@ -33,3 +42,105 @@ func TestPciSource(t *testing.T) {
 	assert.Empty(t, l)
 
 }
+
+func TestDetectPci(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+
+	// Device behind a root port and a switch
+	devDir := "devices/pci0000:00/0000:00:01.0/0000:01:00.0/0000:02:00.0"
+	testutils.WriteFiles(t, filepath.Join(sysfs, devDir), map[string]string{
+		"class":              "0x020000",
+		"vendor":             "0x8086",
+		"device":             "0x1592",
+		"subsystem_vendor":   "0x8086",
+		"subsystem_device":   "0x0002",
+		"revision":           "0x02",
+		"numa_node":          "1",
+		"sriov_numvfs":       "4",
+		"current_link_speed": "16.0 GT/s PCIe",
+		"max_link_width":     "16",
+	})
+	assert.Nil(t, os.MkdirAll(filepath.Join(sysfs, "bus/pci/devices"), 0755))
+	assert.Nil(t, os.Symlink(filepath.Join(sysfs, devDir), filepath.Join(sysfs, "bus/pci/devices/0000:02:00.0")))
+	assert.Nil(t, os.Symlink("../../../../bus/pci/drivers/vfio-pci", filepath.Join(sysfs, devDir, "driver")))
+	assert.Nil(t, os.Symlink("../../../../kernel/iommu_groups/42", filepath.Join(sysfs, devDir, "iommu_group")))
+
+	devs, err := detectPci()
+	assert.Nil(t, err, err)
+	assert.Len(t, devs, 1)
+
+	expected := map[string]string{
+		"class":              "0200",
+		"vendor":             "8086",
+		"device":             "1592",
+		"subsystem_vendor":   "8086",
+		"subsystem_device":   "0002",
+		"revision":           "02",
+		"numa_node":          "1",
+		"sriov_numvfs":       "4",
+		"current_link_speed": "16.0 GT/s PCIe",
+		"max_link_width":     "16",
+		"driver":             "vfio-pci",
+		"iommu_group":        "42",
+		"parent":             "0000:01:00.0",
+		"root_port":          "0000:00:01.0",
+	}
+	assert.Equal(t, expected, devs[0].Attributes)
+}
+
+func TestResolveNames(t *testing.T) {
+	idsFile := filepath.Join(t.TempDir(), "pci.ids")
+	ids := "8086  Intel Corporation\n" +
+		"\t1592  Ethernet Controller E810-C for QSFP\n" +
+		"\t1593  Ethernet Controller E810-C for SFP with a very long name in the pci.ids database\n" +
+		"C 02  Network controller\n" +
+		"\t00  Ethernet controller\n"
+	assert.Nil(t, ioutil.WriteFile(idsFile, []byte(ids), 0644))
+
+	devs := []feature.InstanceFeature{
+		*feature.NewInstanceFeature(map[string]string{"class": "0200", "vendor": "8086", "device": "1592"}),
+		*feature.NewInstanceFeature(map[string]string{"class": "0b40", "vendor": "1234", "device": "0001"}),
+		*feature.NewInstanceFeature(map[string]string{"class": "0200", "vendor": "8086", "device": "1593"}),
+	}
+	assert.Nil(t, hwids.ResolveNames(devs, hwids.FilePaths(idsFile, "", "pci.ids")))
+
+	assert.Equal(t, map[string]string{
+		"class":       "0200",
+		"vendor":      "8086",
+		"device":      "1592",
+		"class_name":  "Ethernet controller",
+		"vendor_name": "Intel Corporation",
+		"device_name": "Ethernet Controller E810-C for QSFP",
+	}, devs[0].Attributes)
+	assert.Equal(t, map[string]string{"class": "0b40", "vendor": "1234", "device": "0001"}, devs[1].Attributes)
+
+	origConfig, origFeatures := src.config, src.features
+	defer func() { src.config, src.features = origConfig, origFeatures }()
+
+	src.config = &Config{
+		DeviceClassWhitelist: []string{"02", "0b40"},
+		DeviceLabelFields:    []string{"class_name", "vendor_name"},
+	}
+	src.features = feature.NewDomainFeatures()
+	src.features.Instances[DeviceFeature] = feature.NewInstanceFeatures(devs)
+
+	l, err := src.GetLabels()
+	assert.Nil(t, err, err)
+	assert.Equal(t, source.FeatureLabels{
+		"Ethernet_controller_Intel_Corporation.present": true,
+		"0b40_1234.present":                             true,
+	}, l)
+
+	// Ids are used instead of names that would make label names too long
+	src.config.DeviceLabelFields = []string{"vendor_name", "device_name"}
+	l, err = src.GetLabels()
+	assert.Nil(t, err, err)
+	assert.Equal(t, source.FeatureLabels{
+		"8086_1592.present": true,
+		"1234_0001.present": true,
+		"8086_1593.present": true,
+	}, l)
+	for k := range l {
+		assert.Empty(t, validation.IsQualifiedName(Name+"-"+k), k)
+	}
+}
@@ -19,6 +19,7 @@ package pci
 import (
 	"fmt"
 	"io/ioutil"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -29,7 +30,12 @@ import (
 )
 
 var mandatoryDevAttrs = []string{"class", "vendor", "device", "subsystem_vendor", "subsystem_device"}
-var optionalDevAttrs = []string{"sriov_totalvfs"}
+var optionalDevAttrs = []string{"sriov_totalvfs", "sriov_numvfs", "revision", "numa_node",
+	"current_link_speed", "current_link_width", "max_link_speed", "max_link_width"}
+
+// linkDevAttrs are device attributes that are symlinks in sysfs, the value
+// being the basename of the link target
+var linkDevAttrs = []string{"driver", "iommu_group"}
 
 // Read a single PCI device attribute
 // A PCI attribute in this context, maps to the corresponding sysfs file
@@ -65,9 +71,51 @@ func readPciDevInfo(devPath string) (*feature.InstanceFeature, error) {
 			attrs[attr] = attrVal
 		}
 	}
+	for _, attr := range linkDevAttrs {
+		if target, err := os.Readlink(filepath.Join(devPath, attr)); err == nil {
+			attrs[attr] = filepath.Base(target)
+		}
+	}
+	if parent, rootPort, err := getPciUpstreamPorts(devPath); err != nil {
+		klog.V(3).Infof("failed to get upstream ports of %s: %v", devPath, err)
+	} else if parent != "" {
+		attrs["parent"] = parent
+		attrs["root_port"] = rootPort
+	}
 	return feature.NewInstanceFeature(attrs), nil
 }
 
+// getPciUpstreamPorts returns the addresses of the parent bridge (i.e. the
+// upstream port the device is connected to) and the root port of a PCI
+// device. Empty strings are returned for devices directly on a root bus.
+func getPciUpstreamPorts(devPath string) (string, string, error) {
+	realPath, err := filepath.EvalSymlinks(devPath)
+	if err != nil {
+		return "", "", err
+	}
+
+	// The sysfs device hierarchy follows the PCI topology, e.g.
+	// /sys/devices/pci0000:00/0000:00:01.0/0000:01:00.0
+	parent, rootPort := "", ""
+	for dir := filepath.Dir(realPath); ; dir = filepath.Dir(dir) {
+		name := filepath.Base(dir)
+		if !isPciAddress(name) {
+			break
+		}
+		if parent == "" {
+			parent = name
+		}
+		rootPort = name
+	}
+	return parent, rootPort, nil
+}
+
+// isPciAddress returns true if s is a PCI device address, e.g. 0000:00:01.0
+func isPciAddress(s string) bool {
+	split := strings.Split(s, ":")
+	return len(split) == 3 && len(split[0]) == 4 && len(split[1]) == 2 && strings.Contains(split[2], ".")
+}
+
 // detectPci detects available PCI devices and retrieves their device attributes.
 // An error is returned if reading any of the mandatory attributes fails.
 func detectPci() ([]feature.InstanceFeature, error) {
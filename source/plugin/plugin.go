@@ -0,0 +1,188 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"google.golang.org/grpc"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	nfdclient "sigs.k8s.io/node-feature-discovery/pkg/nfd-client"
+	pb "sigs.k8s.io/node-feature-discovery/pkg/sourceplugin"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/pkg/version"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// SocketSuffix is the filename suffix of plugin sockets. The name of the
+// plugin (and the feature source) is the filename without the suffix.
+const SocketSuffix = ".sock"
+
+// discoverTimeout is the maximum time for connecting to a plugin and
+// running its discovery
+const discoverTimeout = 10 * time.Second
+
+// pluginSource implements the FeatureSource and LabelSource interfaces for
+// one out-of-process source plugin.
+type pluginSource struct {
+	name     string
+	socket   string
+	features *feature.DomainFeatures
+	labels   source.FeatureLabels
+}
+
+var (
+	_ source.FeatureSource = &pluginSource{}
+	_ source.LabelSource   = &pluginSource{}
+)
+
+// plugins contains all registered plugin sources
+var plugins = make(map[string]*pluginSource)
+
+// Name method of the LabelSource interface
+func (s *pluginSource) Name() string { return s.name }
+
+// Priority method of the LabelSource interface
+func (s *pluginSource) Priority() int { return 0 }
+
+// GetLabels method of the LabelSource interface
+func (s *pluginSource) GetLabels() (source.FeatureLabels, error) {
+	labels := make(source.FeatureLabels, len(s.labels))
+	for k, v := range s.labels {
+		labels[k] = v
+	}
+	return labels, nil
+}
+
+// Discover method of the FeatureSource interface
+func (s *pluginSource) Discover() error {
+	s.features = feature.NewDomainFeatures()
+	s.labels = make(source.FeatureLabels)
+
+	ctx, cancel := context.WithTimeout(context.Background(), discoverTimeout)
+	defer cancel()
+
+	conn, err := grpc.DialContext(ctx, "unix://"+s.socket, grpc.WithInsecure(), grpc.WithBlock(), grpc.FailOnNonTempDialError(true))
+	if err != nil {
+		return fmt.Errorf("failed to connect to plugin socket %q: %w", s.socket, err)
+	}
+	defer conn.Close()
+
+	client := pb.NewSourcePluginClient(conn)
+	reply, err := client.Discover(ctx, &pb.DiscoverRequest{
+		NfdVersion: version.Get(),
+		NodeName:   nfdclient.NodeName(),
+	})
+	if err != nil {
+		return fmt.Errorf("discovery request to plugin %q failed: %w", s.name, err)
+	}
+
+	if f := reply.GetFeatures(); f != nil {
+		if f.Keys != nil {
+			s.features.Keys = f.Keys
+		}
+		if f.Values != nil {
+			s.features.Values = f.Values
+		}
+		if f.Instances != nil {
+			s.features.Instances = f.Instances
+		}
+	}
+	for k, v := range reply.GetLabels() {
+		s.labels[k] = v
+	}
+
+	utils.KlogDump(3, fmt.Sprintf("discovered %s features:", s.name), "  ", s.features)
+
+	return nil
+}
+
+// GetFeatures method of the FeatureSource Interface
+func (s *pluginSource) GetFeatures() *feature.DomainFeatures {
+	if s.features == nil {
+		s.features = feature.NewDomainFeatures()
+	}
+	return s.features
+}
+
+// Register synchronizes the registered plugin sources with the plugin
+// sockets in the given directory. A feature source is registered for each new
+// socket and plugins whose socket has disappeared are unregistered. A
+// non-existent directory is not an error. Returns the names of the newly
+// registered and the unregistered plugins.
+func Register(dir string) ([]string, []string, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, nil, fmt.Errorf("unable to access source plugin directory: %w", err)
+	} else if err != nil {
+		klog.V(1).Infof("source plugin directory %q does not exist", dir)
+	}
+
+	added := []string{}
+	found := make(map[string]struct{})
+	for _, file := range files {
+		if file.Mode()&os.ModeSocket == 0 || !strings.HasSuffix(file.Name(), SocketSuffix) {
+			continue
+		}
+
+		name := strings.TrimSuffix(file.Name(), SocketSuffix)
+		if _, ok := plugins[name]; ok {
+			found[name] = struct{}{}
+			continue
+		}
+		if errs := validation.IsDNS1123Label(name); len(errs) > 0 {
+			klog.Warningf("ignoring source plugin %q: invalid name: %s", name, strings.Join(errs, "; "))
+			continue
+		}
+		if source.GetFeatureSource(name) != nil || source.GetLabelSource(name) != nil {
+			klog.Warningf("ignoring source plugin %q: conflicts with a built-in source", name)
+			continue
+		}
+
+		s := &pluginSource{name: name, socket: filepath.Join(dir, file.Name())}
+		source.Register(s)
+		plugins[name] = s
+		found[name] = struct{}{}
+		added = append(added, name)
+		klog.Infof("registered source plugin %q", name)
+	}
+	sort.Strings(added)
+
+	// Drop plugins whose socket has been removed
+	removed := []string{}
+	for name := range plugins {
+		if _, ok := found[name]; !ok {
+			source.Unregister(name)
+			delete(plugins, name)
+			removed = append(removed, name)
+			klog.Infof("unregistered source plugin %q, socket removed", name)
+		}
+	}
+	sort.Strings(removed)
+
+	return added, removed, nil
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/grpc"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	pb "sigs.k8s.io/node-feature-discovery/pkg/sourceplugin"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+type fakePlugin struct {
+	pb.UnimplementedSourcePluginServer
+}
+
+func (p *fakePlugin) Discover(ctx context.Context, r *pb.DiscoverRequest) (*pb.DiscoverReply, error) {
+	f := feature.NewDomainFeatures()
+	f.Keys["flags"] = feature.NewKeyFeatures("flag-1")
+	return &pb.DiscoverReply{Features: f, Labels: map[string]string{"label-1": "true"}}, nil
+}
+
+func TestPluginSource(t *testing.T) {
+	dir, err := ioutil.TempDir("", "nfd-plugin-test")
+	assert.Nil(t, err, err)
+	defer os.RemoveAll(dir)
+
+	// Non-socket files are ignored
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "not-a-socket.sock"), nil, 0644))
+
+	l, err := net.Listen("unix", filepath.Join(dir, "fake-plugin"+SocketSuffix))
+	assert.Nil(t, err, err)
+	server := grpc.NewServer()
+	pb.RegisterSourcePluginServer(server, &fakePlugin{})
+	go func() { _ = server.Serve(l) }()
+	defer server.Stop()
+
+	added, removed, err := Register(dir)
+	assert.Nil(t, err, err)
+	assert.Equal(t, []string{"fake-plugin"}, added)
+	assert.Empty(t, removed)
+
+	// Registering again is a no-op
+	added, removed, err = Register(dir)
+	assert.Nil(t, err, err)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+
+	s := source.GetFeatureSource("fake-plugin")
+	assert.NotNil(t, s)
+	assert.Equal(t, "fake-plugin", s.Name())
+
+	// Check that GetLabels works with empty features
+	l2, err := source.GetLabelSource("fake-plugin").GetLabels()
+	assert.Nil(t, err, err)
+	assert.Empty(t, l2)
+
+	assert.Nil(t, s.Discover())
+	assert.Equal(t, feature.NewKeyFeatures("flag-1"), s.GetFeatures().Keys["flags"])
+	labels, err := source.GetLabelSource("fake-plugin").GetLabels()
+	assert.Nil(t, err, err)
+	assert.Equal(t, source.FeatureLabels{"label-1": "true"}, labels)
+
+	// Discovery fails if the plugin goes away
+	server.Stop()
+	assert.NotNil(t, s.Discover())
+	assert.Empty(t, s.GetFeatures().Keys)
+
+	// Plugin is unregistered when its socket is removed (which happened
+	// when the server was stopped)
+	added, removed, err = Register(dir)
+	assert.Nil(t, err, err)
+	assert.Empty(t, added)
+	assert.Equal(t, []string{"fake-plugin"}, removed)
+	assert.Nil(t, source.GetFeatureSource("fake-plugin"))
+
+	// Missing directory is not an error
+	added, removed, err = Register(filepath.Join(dir, "missing"))
+	assert.Nil(t, err, err)
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+}
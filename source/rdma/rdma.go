@@ -0,0 +1,287 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rdma
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+const Name = "rdma"
+
+const (
+	DeviceFeature = "device"
+	PortFeature   = "port"
+)
+
+const sysfsBaseDir = "class/infiniband"
+
+// rdmaSource implements the FeatureSource and LabelSource interfaces.
+type rdmaSource struct {
+	features *feature.DomainFeatures
+}
+
+// Singleton source instance
+var (
+	src rdmaSource
+	_   source.FeatureSource = &src
+	_   source.LabelSource   = &src
+)
+
+// Name returns an identifier string for this feature source.
+func (s *rdmaSource) Name() string { return Name }
+
+// Priority method of the LabelSource interface
+func (s *rdmaSource) Priority() int { return 0 }
+
+// GetLabels method of the LabelSource interface
+func (s *rdmaSource) GetLabels() (source.FeatureLabels, error) {
+	labels := source.FeatureLabels{}
+	features := s.GetFeatures()
+
+	maxRate := map[string]int{}
+	for _, port := range features.Instances[PortFeature].Elements {
+		attrs := port.Attributes
+		if attrs["state"] != "ACTIVE" {
+			continue
+		}
+		var prefix string
+		switch attrs["link_layer"] {
+		case "InfiniBand":
+			prefix = "infiniband"
+		case "Ethernet":
+			prefix = "roce"
+		default:
+			continue
+		}
+		labels[prefix+".active"] = true
+
+		if rate, err := strconv.Atoi(attrs["rate"]); err == nil && rate > maxRate[prefix] {
+			maxRate[prefix] = rate
+		}
+	}
+	// Labels advertise the rate in Gb/s, e.g. "2.5" for 1X SDR
+	for prefix, rate := range maxRate {
+		labels[prefix+".max_rate"] = strconv.FormatFloat(float64(rate)/1000, 'f', -1, 64)
+	}
+	return labels, nil
+}
+
+// Discover method of the FeatureSource interface.
+func (s *rdmaSource) Discover() error {
+	s.features = feature.NewDomainFeatures()
+
+	devs, ports, err := detectRdmaDevices()
+	if err != nil {
+		return fmt.Errorf("failed to detect RDMA devices: %w", err)
+	}
+	s.features.Instances[DeviceFeature] = feature.NewInstanceFeatures(devs)
+	s.features.Instances[PortFeature] = feature.NewInstanceFeatures(ports)
+
+	utils.KlogDump(3, "discovered rdma features:", "  ", s.features)
+
+	return nil
+}
+
+// GetFeatures method of the FeatureSource Interface.
+func (s *rdmaSource) GetFeatures() *feature.DomainFeatures {
+	if s.features == nil {
+		s.features = feature.NewDomainFeatures()
+	}
+	return s.features
+}
+
+// detectRdmaDevices detects RDMA devices and their ports from sysfs.
+func detectRdmaDevices() ([]feature.InstanceFeature, []feature.InstanceFeature, error) {
+	sysfsBasePath := source.SysfsDir.Path(sysfsBaseDir)
+
+	devices, err := ioutil.ReadDir(sysfsBasePath)
+	if os.IsNotExist(err) {
+		klog.V(1).Infof("no RDMA devices found (%s does not exist)", sysfsBasePath)
+		return nil, nil, nil
+	} else if err != nil {
+		return nil, nil, fmt.Errorf("failed to list RDMA devices: %w", err)
+	}
+
+	devs := make([]feature.InstanceFeature, 0, len(devices))
+	ports := make([]feature.InstanceFeature, 0)
+	for _, device := range devices {
+		devPath := filepath.Join(sysfsBasePath, device.Name())
+
+		netdevs := getNetdevs(devPath)
+		devs = append(devs, *feature.NewInstanceFeature(readDevInfo(devPath, netdevs)))
+		ports = append(ports, readPorts(devPath, netdevs)...)
+	}
+
+	return devs, ports, nil
+}
+
+// readDevInfo reads the attributes of one RDMA device.
+func readDevInfo(devPath string, netdevs map[string]int) map[string]string {
+	attrs := map[string]string{"name": filepath.Base(devPath)}
+
+	if v, err := readAttr(devPath, "node_type"); err == nil {
+		attrs["node_type"] = stripNumericPrefix(v)
+	}
+	if v, err := readAttr(devPath, "fw_ver"); err == nil {
+		attrs["fw_ver"] = v
+	}
+	if v, err := readAttr(devPath, "node_guid"); err == nil {
+		attrs["node_guid_present"] = strconv.FormatBool(strings.Trim(v, "0:") != "")
+	}
+	if realPath, err := filepath.EvalSymlinks(filepath.Join(devPath, "device")); err == nil {
+		attrs["pci_address"] = filepath.Base(realPath)
+	}
+	if len(netdevs) > 0 {
+		names := make([]string, 0, len(netdevs))
+		for name := range netdevs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		attrs["netdevs"] = strings.Join(names, ",")
+	}
+
+	return attrs
+}
+
+// readPorts reads the attributes of the ports of one RDMA device.
+func readPorts(devPath string, netdevs map[string]int) []feature.InstanceFeature {
+	portsPath := filepath.Join(devPath, "ports")
+	entries, err := ioutil.ReadDir(portsPath)
+	if err != nil {
+		klog.Errorf("failed to list ports of RDMA device %s: %v", filepath.Base(devPath), err)
+		return nil
+	}
+
+	ports := make([]feature.InstanceFeature, 0, len(entries))
+	for _, entry := range entries {
+		portNum, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+		portPath := filepath.Join(portsPath, entry.Name())
+
+		attrs := map[string]string{
+			"device": filepath.Base(devPath),
+			"port":   entry.Name(),
+		}
+		if v, err := readAttr(portPath, "link_layer"); err == nil {
+			attrs["link_layer"] = v
+		}
+		if v, err := readAttr(portPath, "state"); err == nil {
+			attrs["state"] = stripNumericPrefix(v)
+		}
+		if v, err := readAttr(portPath, "phys_state"); err == nil {
+			attrs["phys_state"] = stripNumericPrefix(v)
+		}
+		if v, err := readAttr(portPath, "rate"); err == nil {
+			if rate, err := parseRate(v); err != nil {
+				klog.Errorf("failed to parse rate of %s port %d: %v", filepath.Base(devPath), portNum, err)
+			} else {
+				attrs["rate"] = strconv.Itoa(rate)
+			}
+		}
+		if netdev := portNetdev(portPath, portNum, netdevs); netdev != "" {
+			attrs["netdev"] = netdev
+		}
+
+		ports = append(ports, *feature.NewInstanceFeature(attrs))
+	}
+	return ports
+}
+
+// getNetdevs returns the network interfaces of the (PCI) device an RDMA
+// device is associated with, mapped to their port index (dev_port).
+func getNetdevs(devPath string) map[string]int {
+	entries, err := ioutil.ReadDir(filepath.Join(devPath, "device", "net"))
+	if err != nil {
+		return nil
+	}
+	netdevs := make(map[string]int, len(entries))
+	for _, entry := range entries {
+		devPort := 0
+		if v, err := readAttr(devPath, "device", "net", entry.Name(), "dev_port"); err == nil {
+			devPort, _ = strconv.Atoi(v)
+		}
+		netdevs[entry.Name()] = devPort
+	}
+	return netdevs
+}
+
+// portNetdev returns the network interface associated with an RDMA port. The
+// GID table is used (RoCE) with a fall back to matching the dev_port of the
+// network interfaces of the device (e.g. IPoIB).
+func portNetdev(portPath string, portNum int, netdevs map[string]int) string {
+	if v, err := readAttr(portPath, "gid_attrs", "ndevs", "0"); err == nil && v != "" {
+		return v
+	}
+	for name, devPort := range netdevs {
+		if devPort == portNum-1 {
+			return name
+		}
+	}
+	return ""
+}
+
+// parseRate parses the port rate from sysfs, e.g. "100 Gb/sec (4X EDR)", into
+// an integer in Mb/s.
+func parseRate(s string) (int, error) {
+	split := strings.Fields(s)
+	if len(split) < 2 || split[1] != "Gb/sec" {
+		return 0, fmt.Errorf("unexpected rate format %q", s)
+	}
+	rate, err := strconv.ParseFloat(split[0], 64)
+	if err != nil {
+		return 0, err
+	}
+	return int(math.Round(rate * 1000)), nil
+}
+
+// stripNumericPrefix strips the numeric code from sysfs values like
+// "4: ACTIVE".
+func stripNumericPrefix(s string) string {
+	if i := strings.Index(s, ":"); i >= 0 {
+		if _, err := strconv.Atoi(s[:i]); err == nil {
+			return strings.TrimSpace(s[i+1:])
+		}
+	}
+	return s
+}
+
+func readAttr(elem ...string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(elem...))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func init() {
+	source.Register(&src)
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rdma
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/source"
+	"sigs.k8s.io/node-feature-discovery/source/testutils"
+)
+
+func TestRdmaSource(t *testing.T) {
+	assert.Equal(t, src.Name(), Name)
+
+	// Check that GetLabels works with empty features
+	src.features = nil
+	l, err := src.GetLabels()
+
+	assert.Nil(t, err, err)
+	assert.Empty(t, l)
+}
+
+func TestDetectRdmaDevices(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+
+	// InfiniBand HCA with an IPoIB interface
+	testutils.WriteFiles(t, filepath.Join(sysfs, "devices/pci0000:00/0000:00:01.0/0000:01:00.0"), map[string]string{
+		"net/ib0/dev_port": "0",
+	})
+	testutils.WriteFiles(t, filepath.Join(sysfs, "devices/pci0000:00/0000:00:01.0/0000:01:00.0/infiniband/mlx5_0"), map[string]string{
+		"node_type":          "1: CA",
+		"fw_ver":             "20.31.1014",
+		"node_guid":          "0c42:a103:0065:2a5c",
+		"ports/1/link_layer": "InfiniBand",
+		"ports/1/state":      "4: ACTIVE",
+		"ports/1/phys_state": "5: LinkUp",
+		"ports/1/rate":       "200 Gb/sec (4X HDR)",
+	})
+	// RoCE device with the link down
+	testutils.WriteFiles(t, filepath.Join(sysfs, "devices/pci0000:00/0000:00:02.0/0000:02:00.0"), map[string]string{
+		"net/eth0/dev_port": "0",
+	})
+	testutils.WriteFiles(t, filepath.Join(sysfs, "devices/pci0000:00/0000:00:02.0/0000:02:00.0/infiniband/mlx5_1"), map[string]string{
+		"node_type":                 "1: CA",
+		"fw_ver":                    "14.32.1010",
+		"node_guid":                 "0000:0000:0000:0000",
+		"ports/1/link_layer":        "Ethernet",
+		"ports/1/state":             "1: DOWN",
+		"ports/1/phys_state":        "3: Disabled",
+		"ports/1/rate":              "2.5 Gb/sec (1X SDR)",
+		"ports/1/gid_attrs/ndevs/0": "eth0",
+	})
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(sysfs, sysfsBaseDir), 0755))
+	for i, pciDev := range []string{"0000:00:01.0/0000:01:00.0", "0000:00:02.0/0000:02:00.0"} {
+		name := []string{"mlx5_0", "mlx5_1"}[i]
+		devDir := filepath.Join(sysfs, "devices/pci0000:00", pciDev)
+		assert.Nil(t, os.Symlink(devDir, filepath.Join(devDir, "infiniband", name, "device")))
+		assert.Nil(t, os.Symlink(filepath.Join(devDir, "infiniband", name), filepath.Join(sysfs, sysfsBaseDir, name)))
+	}
+
+	devs, ports, err := detectRdmaDevices()
+	assert.Nil(t, err, err)
+
+	expectedDevs := []feature.InstanceFeature{
+		*feature.NewInstanceFeature(map[string]string{
+			"name":              "mlx5_0",
+			"node_type":         "CA",
+			"fw_ver":            "20.31.1014",
+			"node_guid_present": "true",
+			"pci_address":       "0000:01:00.0",
+			"netdevs":           "ib0",
+		}),
+		*feature.NewInstanceFeature(map[string]string{
+			"name":              "mlx5_1",
+			"node_type":         "CA",
+			"fw_ver":            "14.32.1010",
+			"node_guid_present": "false",
+			"pci_address":       "0000:02:00.0",
+			"netdevs":           "eth0",
+		}),
+	}
+	expectedPorts := []feature.InstanceFeature{
+		*feature.NewInstanceFeature(map[string]string{
+			"device":     "mlx5_0",
+			"port":       "1",
+			"link_layer": "InfiniBand",
+			"state":      "ACTIVE",
+			"phys_state": "LinkUp",
+			"rate":       "200000",
+			"netdev":     "ib0",
+		}),
+		*feature.NewInstanceFeature(map[string]string{
+			"device":     "mlx5_1",
+			"port":       "1",
+			"link_layer": "Ethernet",
+			"state":      "DOWN",
+			"phys_state": "Disabled",
+			"rate":       "2500",
+			"netdev":     "eth0",
+		}),
+	}
+	assert.Equal(t, expectedDevs, devs)
+	assert.Equal(t, expectedPorts, ports)
+
+	origFeatures := src.features
+	defer func() { src.features = origFeatures }()
+
+	src.features = feature.NewDomainFeatures()
+	src.features.Instances[PortFeature] = feature.NewInstanceFeatures(ports)
+
+	l, err := src.GetLabels()
+	assert.Nil(t, err, err)
+	assert.Equal(t, source.FeatureLabels{"infiniband.active": true, "infiniband.max_rate": "200"}, l)
+
+	// Fractional rates are not rounded in labels
+	ports[1].Attributes["state"] = "ACTIVE"
+	l, err = src.GetLabels()
+	assert.Nil(t, err, err)
+	assert.Equal(t, "2.5", l["roce.max_rate"])
+}
+
+func TestParseRate(t *testing.T) {
+	for in, expected := range map[string]int{
+		"100 Gb/sec (4X EDR)": 100000,
+		"2.5 Gb/sec (1X SDR)": 2500,
+		"56 Gb/sec (4X FDR)":  56000,
+		"400 Gb/sec (4X NDR)": 400000,
+		"10 Gb/sec (1X QDR)":  10000,
+		"25 Gb/sec (1X EDR)":  25000,
+		"0 Gb/sec":            0,
+	} {
+		rate, err := parseRate(in)
+		assert.Nil(t, err, err)
+		assert.Equal(t, expected, rate, in)
+	}
+
+	_, err := parseRate("invalid")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// efiGlobalVariableGUID is the vendor GUID of the global UEFI variables
+const efiGlobalVariableGUID = "8be4df61-93ca-11d2-aa0d-00e098032b8c"
+
+// getTpmInfo detects the presence and version of a TPM device
+func getTpmInfo() map[string]string {
+	info := map[string]string{"present": "false"}
+
+	devs, err := ioutil.ReadDir(source.SysfsDir.Path("class/tpm"))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			klog.V(1).Infof("failed to read tpm devices: %v", err)
+		}
+		return info
+	}
+	if len(devs) == 0 {
+		return info
+	}
+	info["present"] = "true"
+
+	// Only consider the first device
+	dev := devs[0].Name()
+	if major, err := readSysfsValue("class/tpm", dev, "tpm_version_major"); err == nil {
+		switch major {
+		case "1":
+			info["version"] = "1.2"
+		case "2":
+			info["version"] = "2.0"
+		default:
+			info["version"] = major
+		}
+	} else if _, err := os.Stat(source.SysfsDir.Path("class/tpm", dev, "caps")); err == nil {
+		// Older kernels only expose the caps file for TPM 1.2 devices
+		info["version"] = "1.2"
+	}
+
+	return info
+}
+
+// secureBootEnabled detects if UEFI Secure Boot is enabled by reading the
+// SecureBoot efi variable
+func secureBootEnabled() (bool, error) {
+	data, err := ioutil.ReadFile(source.SysfsDir.Path("firmware/efi/efivars", "SecureBoot-"+efiGlobalVariableGUID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			if _, err := os.Stat(source.SysfsDir.Path("firmware/efi")); os.IsNotExist(err) {
+				// Not booted in UEFI mode
+				return false, nil
+			}
+		}
+		return false, err
+	}
+	// The first four bytes are the attributes of the variable
+	if len(data) < 5 {
+		return false, fmt.Errorf("invalid SecureBoot efi variable (size %d)", len(data))
+	}
+	return data[4] == 1, nil
+}
+
+// getLockdownMode returns the active kernel lockdown mode
+func getLockdownMode() (string, error) {
+	data, err := ioutil.ReadFile(source.SysfsDir.Path("kernel/security/lockdown"))
+	if err != nil {
+		return "", err
+	}
+	// The active mode is enclosed in brackets, e.g. "[none] integrity confidentiality"
+	for _, mode := range strings.Fields(string(data)) {
+		if strings.HasPrefix(mode, "[") && strings.HasSuffix(mode, "]") {
+			return strings.Trim(mode, "[]"), nil
+		}
+	}
+	return "", fmt.Errorf("unable to parse lockdown mode from %q", strings.TrimSpace(string(data)))
+}
+
+// getLsms returns the active Linux Security Modules
+func getLsms() ([]string, error) {
+	data, err := ioutil.ReadFile(source.SysfsDir.Path("kernel/security/lsm"))
+	if err != nil {
+		return nil, err
+	}
+	lsms := []string{}
+	for _, lsm := range strings.Split(strings.TrimSpace(string(data)), ",") {
+		if lsm != "" {
+			lsms = append(lsms, lsm)
+		}
+	}
+	return lsms, nil
+}
+
+// getSevInfo detects AMD SEV host capabilities from the kvm_amd module
+// parameters
+func getSevInfo() map[string]string {
+	info := make(map[string]string)
+	for param, name := range map[string]string{"sev": "enabled", "sev_es": "es.enabled", "sev_snp": "snp.enabled"} {
+		if v, err := readSysfsValue("module/kvm_amd/parameters", param); err == nil {
+			info[name] = fmt.Sprint(isParamEnabled(v))
+		}
+	}
+	return info
+}
+
+// getTdxInfo detects Intel TDX host capabilities from the kvm_intel module
+// parameters
+func getTdxInfo() map[string]string {
+	info := make(map[string]string)
+	if v, err := readSysfsValue("module/kvm_intel/parameters", "tdx"); err == nil {
+		info["enabled"] = fmt.Sprint(isParamEnabled(v))
+	}
+	return info
+}
+
+// isParamEnabled interprets the value of a boolean kernel module parameter
+func isParamEnabled(v string) bool {
+	return v == "Y" || v == "y" || v == "1"
+}
+
+func readSysfsValue(elem ...string) (string, error) {
+	data, err := ioutil.ReadFile(source.SysfsDir.Path(elem...))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+const Name = "security"
+
+const (
+	LockdownFeature   = "lockdown"
+	LsmFeature        = "lsm"
+	SecureBootFeature = "secureboot"
+	SevFeature        = "sev"
+	TdxFeature        = "tdx"
+	TpmFeature        = "tpm"
+)
+
+// securitySource implements the FeatureSource and LabelSource interfaces.
+type securitySource struct {
+	features *feature.DomainFeatures
+}
+
+// Singleton source instance
+var (
+	src securitySource
+	_   source.FeatureSource = &src
+	_   source.LabelSource   = &src
+)
+
+// Name returns the name of the feature source
+func (s *securitySource) Name() string { return Name }
+
+// Priority method of the LabelSource interface
+func (s *securitySource) Priority() int { return 0 }
+
+// GetLabels method of the LabelSource interface
+func (s *securitySource) GetLabels() (source.FeatureLabels, error) {
+	labels := source.FeatureLabels{}
+	features := s.GetFeatures()
+
+	tpm := features.Values[TpmFeature].Elements
+	if tpm["present"] == "true" {
+		labels["tpm.present"] = true
+		if v, ok := tpm["version"]; ok {
+			labels["tpm.version"] = v
+		}
+	}
+
+	if v, ok := features.Values[SecureBootFeature].Elements["enabled"]; ok {
+		labels["secureboot.enabled"] = v
+	}
+
+	if v, ok := features.Values[LockdownFeature].Elements["mode"]; ok {
+		labels["lockdown.mode"] = v
+	}
+
+	for _, k := range []string{"enabled", "es.enabled", "snp.enabled"} {
+		if features.Values[SevFeature].Elements[k] == "true" {
+			labels["sev."+k] = true
+		}
+	}
+
+	if features.Values[TdxFeature].Elements["enabled"] == "true" {
+		labels["tdx.enabled"] = true
+	}
+
+	return labels, nil
+}
+
+// Discover method of the FeatureSource interface
+func (s *securitySource) Discover() error {
+	s.features = feature.NewDomainFeatures()
+
+	// Detect TPM
+	s.features.Values[TpmFeature] = feature.NewValueFeatures(getTpmInfo())
+
+	// Detect UEFI Secure Boot
+	if enabled, err := secureBootEnabled(); err != nil {
+		klog.V(1).Infof("unable to detect secure boot state: %v", err)
+	} else {
+		s.features.Values[SecureBootFeature] = feature.NewValueFeatures(nil)
+		s.features.Values[SecureBootFeature].Elements["enabled"] = strconv.FormatBool(enabled)
+	}
+
+	// Detect kernel lockdown mode
+	if mode, err := getLockdownMode(); err != nil {
+		klog.V(1).Infof("unable to detect kernel lockdown mode: %v", err)
+	} else {
+		s.features.Values[LockdownFeature] = feature.NewValueFeatures(map[string]string{"mode": mode})
+	}
+
+	// Detect active LSMs
+	if lsms, err := getLsms(); err != nil {
+		klog.V(1).Infof("unable to detect active LSMs: %v", err)
+	} else {
+		s.features.Keys[LsmFeature] = feature.NewKeyFeatures(lsms...)
+	}
+
+	// Detect confidential computing host capabilities
+	s.features.Values[SevFeature] = feature.NewValueFeatures(getSevInfo())
+	s.features.Values[TdxFeature] = feature.NewValueFeatures(getTdxInfo())
+
+	utils.KlogDump(3, "discovered security features:", "  ", s.features)
+
+	return nil
+}
+
+// GetFeatures method of the FeatureSource Interface
+func (s *securitySource) GetFeatures() *feature.DomainFeatures {
+	if s.features == nil {
+		s.features = feature.NewDomainFeatures()
+	}
+	return s.features
+}
+
+func init() {
+	source.Register(&src)
+}
@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package security
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/source"
+	"sigs.k8s.io/node-feature-discovery/source/testutils"
+)
+
+func TestSecuritySource(t *testing.T) {
+	assert.Equal(t, src.Name(), Name)
+
+	// Check that GetLabels works with empty features
+	src.features = nil
+	l, err := src.GetLabels()
+
+	assert.Nil(t, err, err)
+	assert.Empty(t, l)
+}
+
+func TestDiscover(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+	testutils.WriteFiles(t, sysfs, map[string]string{
+		"class/tpm/tpm0/tpm_version_major": "2",
+		"kernel/security/lockdown":         "none [integrity] confidentiality",
+		"kernel/security/lsm":              "lockdown,capability,yama,apparmor",
+		"module/kvm_amd/parameters/sev":    "Y",
+		"module/kvm_amd/parameters/sev_es": "N",
+	})
+	// EFI variables are binary data
+	efivar := filepath.Join(sysfs, "firmware/efi/efivars/SecureBoot-8be4df61-93ca-11d2-aa0d-00e098032b8c")
+	assert.Nil(t, os.MkdirAll(filepath.Dir(efivar), 0755))
+	assert.Nil(t, ioutil.WriteFile(efivar, []byte("\x06\x00\x00\x00\x01"), 0644))
+
+	assert.Nil(t, src.Discover())
+	l, err := src.GetLabels()
+	assert.Nil(t, err, err)
+
+	expected := source.FeatureLabels{
+		"tpm.present":        true,
+		"tpm.version":        "2.0",
+		"secureboot.enabled": "true",
+		"lockdown.mode":      "integrity",
+		"sev.enabled":        true,
+	}
+	assert.Equal(t, expected, l)
+
+	lsms := src.GetFeatures().Keys[LsmFeature].Elements
+	assert.Len(t, lsms, 4)
+	assert.Contains(t, lsms, "apparmor")
+	assert.Equal(t, "false", src.GetFeatures().Values[SevFeature].Elements["es.enabled"])
+}
@@ -66,6 +66,25 @@ type ConfigurableSource interface {
 	SetConfig(Config)
 }
 
+// ValidatingSource is an interface for a configurable source that is able to
+// check its configuration for semantic errors
+type ValidatingSource interface {
+	ConfigurableSource
+
+	// ValidateConfig returns all errors found in the given configuration
+	ValidateConfig(Config) []error
+}
+
+// WatchableSource is an interface for a source whose input data lives in the
+// filesystem and changes of which should trigger re-discovery
+type WatchableSource interface {
+	Source
+
+	// WatchPaths returns the files and directories the source reads its
+	// input from
+	WatchPaths() []string
+}
+
 // TestSource represents a source purposed for testing only
 type TestSource interface {
 	Source
@@ -95,6 +114,11 @@ func Register(s Source) {
 	sources[s.Name()] = s
 }
 
+// Unregister removes a registered source
+func Unregister(name string) {
+	delete(sources, name)
+}
+
 // GetFeatureSource returns a registered FeatureSource interface
 func GetFeatureSource(name string) FeatureSource {
 	if s, ok := sources[name].(FeatureSource); ok {
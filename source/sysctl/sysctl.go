@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysctl
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+const Name = "sysctl"
+
+const ParameterFeature = "parameter"
+
+// sysctlProcfsPath is the root of the sysctl parameters in procfs
+const sysctlProcfsPath = "/proc/sys"
+
+// Config holds the configuration parameters of this source.
+type Config struct {
+	Keys      []string `json:"keys,omitempty"`
+	LabelKeys []string `json:"labelKeys,omitempty"`
+}
+
+// newDefaultConfig returns a new config with pre-populated defaults
+func newDefaultConfig() *Config {
+	return &Config{
+		Keys: []string{
+			"kernel.sched_rt_runtime_us",
+			"vm.nr_hugepages",
+		},
+	}
+}
+
+// sysctlSource implements the FeatureSource, LabelSource and ConfigurableSource interfaces.
+type sysctlSource struct {
+	config   *Config
+	features *feature.DomainFeatures
+}
+
+// Singleton source instance
+var (
+	src                           = sysctlSource{config: newDefaultConfig()}
+	_   source.FeatureSource      = &src
+	_   source.LabelSource        = &src
+	_   source.ConfigurableSource = &src
+	_   source.ValidatingSource   = &src
+)
+
+// Name returns the name of the feature source
+func (s *sysctlSource) Name() string { return Name }
+
+// NewConfig method of the LabelSource interface
+func (s *sysctlSource) NewConfig() source.Config { return newDefaultConfig() }
+
+// GetConfig method of the LabelSource interface
+func (s *sysctlSource) GetConfig() source.Config { return s.config }
+
+// SetConfig method of the LabelSource interface
+func (s *sysctlSource) SetConfig(conf source.Config) {
+	switch v := conf.(type) {
+	case *Config:
+		s.config = v
+	default:
+		klog.Fatalf("invalid config type: %T", conf)
+	}
+}
+
+// ValidateConfig method of the ValidatingSource interface
+func (s *sysctlSource) ValidateConfig(conf source.Config) []error {
+	c, ok := conf.(*Config)
+	if !ok {
+		return []error{fmt.Errorf("invalid config type: %T", conf)}
+	}
+
+	errs := []error{}
+	keys := make(map[string]struct{}, len(c.Keys))
+	for _, k := range c.Keys {
+		if !isValidKey(k) {
+			errs = append(errs, fmt.Errorf("invalid sysctl key %q in keys", k))
+		}
+		keys[k] = struct{}{}
+	}
+	for _, k := range c.LabelKeys {
+		if _, ok := keys[k]; !ok {
+			errs = append(errs, fmt.Errorf("key %q in labelKeys not specified in keys", k))
+		}
+		if err := validateLabelKey(k); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// Priority method of the LabelSource interface
+func (s *sysctlSource) Priority() int { return 0 }
+
+// GetLabels method of the LabelSource interface
+func (s *sysctlSource) GetLabels() (source.FeatureLabels, error) {
+	labels := source.FeatureLabels{}
+	features := s.GetFeatures()
+
+	for _, k := range s.config.LabelKeys {
+		if err := validateLabelKey(k); err != nil {
+			klog.Warningf("%v, ignoring...", err)
+			continue
+		}
+		if v, ok := features.Values[ParameterFeature].Elements[k]; ok {
+			if v = utils.SanitizeLabelValue(v); v != "" {
+				labels[k] = v
+			}
+		}
+	}
+	return labels, nil
+}
+
+// Discover method of the FeatureSource interface
+func (s *sysctlSource) Discover() error {
+	s.features = feature.NewDomainFeatures()
+
+	params := make(map[string]string, len(s.config.Keys))
+	for _, k := range s.config.Keys {
+		if !isValidKey(k) {
+			klog.Warningf("invalid sysctl key %q, ignoring...", k)
+			continue
+		}
+		v, err := readSysctl(k)
+		if err != nil {
+			klog.V(1).Infof("failed to read sysctl %q: %v", k, err)
+			continue
+		}
+		params[k] = v
+	}
+	s.features.Values[ParameterFeature] = feature.NewValueFeatures(params)
+
+	utils.KlogDump(3, "discovered sysctl features:", "  ", s.features)
+
+	return nil
+}
+
+// GetFeatures method of the FeatureSource Interface
+func (s *sysctlSource) GetFeatures() *feature.DomainFeatures {
+	if s.features == nil {
+		s.features = feature.NewDomainFeatures()
+	}
+	return s.features
+}
+
+var validKey = regexp.MustCompile(`^[A-Za-z0-9_-]+([./][A-Za-z0-9_-]+)*$`)
+
+// isValidKey checks that a sysctl key is well-formed and refers to a file
+// under /proc/sys
+func isValidKey(key string) bool {
+	return validKey.MatchString(key)
+}
+
+// validateLabelKey checks that a sysctl key can be used in a label name
+func validateLabelKey(key string) error {
+	if errs := validation.IsQualifiedName(Name + "-" + key); len(errs) > 0 {
+		return fmt.Errorf("key %q in labelKeys cannot be used as a label name: %s", key, strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// keyToPath converts a sysctl key into a path relative to /proc/sys. Keys
+// containing slashes are used as paths as-is (which allows e.g. network
+// interface names with dots), otherwise dots are used as separators.
+func keyToPath(key string) string {
+	if strings.Contains(key, "/") {
+		return key
+	}
+	return strings.Replace(key, ".", "/", -1)
+}
+
+// readSysctl reads the value of a sysctl parameter
+func readSysctl(key string) (string, error) {
+	data, err := ioutil.ReadFile(filepath.Join(sysctlProcfsPath, keyToPath(key)))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+func init() {
+	source.Register(&src)
+}
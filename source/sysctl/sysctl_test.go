@@ -0,0 +1,69 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sysctl
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+func TestSysctlSource(t *testing.T) {
+	assert.Equal(t, src.Name(), Name)
+
+	// Check that GetLabels works with empty features
+	src.features = nil
+	l, err := src.GetLabels()
+
+	assert.Nil(t, err, err)
+	assert.Empty(t, l)
+}
+
+func TestValidateConfig(t *testing.T) {
+	c := &Config{
+		Keys:      []string{"vm.nr_hugepages", "net/ipv4/conf/eth0.100/forwarding", "../etc/passwd", "kernel..foo"},
+		LabelKeys: []string{"vm.nr_hugepages", "vm.swappiness", "net/ipv4/conf/eth0.100/forwarding"},
+	}
+	errs := src.ValidateConfig(c)
+	assert.Len(t, errs, 4)
+
+	assert.Equal(t, "net/ipv4/conf/eth0.100/forwarding", keyToPath("net/ipv4/conf/eth0.100/forwarding"))
+	assert.Equal(t, "vm/nr_hugepages", keyToPath("vm.nr_hugepages"))
+}
+
+func TestGetLabels(t *testing.T) {
+	origConfig, origFeatures := src.config, src.features
+	defer func() { src.config, src.features = origConfig, origFeatures }()
+
+	src.config = &Config{LabelKeys: []string{"kernel.core_pattern", "net/ipv4/ip_forward", "vm.nr_hugepages"}}
+	src.features = feature.NewDomainFeatures()
+	src.features.Values[ParameterFeature] = feature.NewValueFeatures(map[string]string{
+		"kernel.core_pattern": "|/usr/lib/systemd/systemd-coredump %P %u",
+		"net/ipv4/ip_forward": "1",
+		"vm.nr_hugepages":     "128",
+	})
+
+	l, err := src.GetLabels()
+	assert.Nil(t, err, err)
+	assert.Equal(t, source.FeatureLabels{
+		"kernel.core_pattern": "usr_lib_systemd_systemd-coredump_P_u",
+		"vm.nr_hugepages":     "128",
+	}, l)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutils contains helpers for testing feature sources against
+// fake host filesystem trees.
+package testutils
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// FakeSysfs creates an empty temporary directory and makes it the sysfs root
+// of feature sources for the duration of the test.
+func FakeSysfs(t *testing.T) string {
+	t.Helper()
+
+	sysfs := t.TempDir()
+	orig := source.SysfsDir
+	source.SysfsDir = source.HostDir(sysfs)
+	t.Cleanup(func() { source.SysfsDir = orig })
+
+	return sysfs
+}
+
+// WriteFiles writes files under dir, creating parent directories as needed.
+// Like sysfs attributes, the file contents are terminated by a newline.
+func WriteFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content+"\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"k8s.io/klog/v2"
 
 	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/hwids"
 	"sigs.k8s.io/node-feature-discovery/pkg/utils"
 	"sigs.k8s.io/node-feature-discovery/source"
 )
@@ -31,9 +32,19 @@ const Name = "usb"
 
 const DeviceFeature = "device"
 
+// maxDevLabelLen is the maximum length of the device part of label names that
+// keeps the label names within the 63 character limit
+const maxDevLabelLen = 63 - len(Name+"-") - len(".present")
+
 type Config struct {
 	DeviceClassWhitelist []string `json:"deviceClassWhitelist,omitempty"`
 	DeviceLabelFields    []string `json:"deviceLabelFields,omitempty"`
+	// ResolveNames enables resolving of vendor, device and class names from
+	// the usb.ids database
+	ResolveNames bool `json:"resolveNames,omitempty"`
+	// IDsFile is the path to the usb.ids database, overriding the default
+	// search paths
+	IDsFile string `json:"idsFile,omitempty"`
 }
 
 // newDefaultConfig returns a new config with pre-populated defaults
@@ -97,7 +108,7 @@ func (s *usbSource) GetLabels() (source.FeatureLabels, error) {
 		configLabelFields[field] = true
 	}
 
-	for _, attr := range devAttrs {
+	for _, attr := range append(devAttrs, hwids.NameAttrs...) {
 		if _, ok := configLabelFields[attr]; ok {
 			deviceLabelFields = append(deviceLabelFields, attr)
 			delete(configLabelFields, attr)
@@ -121,13 +132,7 @@ func (s *usbSource) GetLabels() (source.FeatureLabels, error) {
 		class := attrs["class"]
 		for _, white := range s.config.DeviceClassWhitelist {
 			if strings.HasPrefix(string(class), strings.ToLower(white)) {
-				devLabel := ""
-				for i, attr := range deviceLabelFields {
-					devLabel += attrs[attr]
-					if i < len(deviceLabelFields)-1 {
-						devLabel += "_"
-					}
-				}
+				devLabel := hwids.DeviceLabel(attrs, deviceLabelFields, maxDevLabelLen)
 				labels[devLabel+".present"] = true
 				break
 			}
@@ -144,6 +149,11 @@ func (s *usbSource) Discover() error {
 	if err != nil {
 		return fmt.Errorf("failed to detect USB devices: %s", err.Error())
 	}
+	if s.config.ResolveNames {
+		if err := hwids.ResolveNames(devs, hwids.FilePaths(s.config.IDsFile, source.UsrDir.Path(), "usb.ids")); err != nil {
+			klog.Warningf("failed to resolve USB device names: %v", err)
+		}
+	}
 	s.features.Instances[DeviceFeature] = feature.NewInstanceFeatures(devs)
 
 	utils.KlogDump(3, "discovered usb features:", "  ", s.features)
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virt
+
+import (
+	"io/ioutil"
+	"strings"
+
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+// hypervisorNames maps hypervisor identifiers (cpuid signatures and
+// /sys/hypervisor/type values) to vendor names
+var hypervisorNames = map[string]string{
+	"KVMKVMKVM":    "KVM",
+	"Microsoft Hv": "Microsoft",
+	"VMwareVMware": "VMware",
+	"XenVMMXenVMM": "Xen",
+	"xen":          "Xen",
+	"TCGTCGTCGTCG": "QEMU",
+	"ACRNACRNACRN": "ACRN",
+	" lrpepyh  vr": "Parallels",
+	"bhyve bhyve ": "bhyve",
+	"VBoxVBoxVBox": "VirtualBox",
+}
+
+// dmiHypervisors maps DMI system vendors and product names that only virtual
+// machines report to hypervisor vendor names. Cloud vendor names are not
+// included as they are reported by bare metal instances, too.
+var dmiHypervisors = []struct {
+	field, value, name string
+}{
+	{"sys_vendor", "QEMU", "QEMU"},
+	{"sys_vendor", "VMware, Inc.", "VMware"},
+	{"sys_vendor", "Xen", "Xen"},
+	{"sys_vendor", "innotek GmbH", "VirtualBox"},
+	{"sys_vendor", "Parallels Software International Inc.", "Parallels"},
+	{"product_name", "KVM", "KVM"},
+	{"product_name", "VirtualBox", "VirtualBox"},
+	{"product_name", "Virtual Machine", "Microsoft"},
+	{"product_name", "VMware Virtual Platform", "VMware"},
+}
+
+// dmiHypervisor detects the hypervisor from DMI information
+func dmiHypervisor() (string, bool) {
+	for _, h := range dmiHypervisors {
+		v, err := readSysfsString("class/dmi/id", h.field)
+		if err == nil && v == h.value {
+			return h.name, true
+		}
+	}
+	return "", false
+}
+
+// hypervisorName returns the vendor name for a cpuid hypervisor signature
+func hypervisorName(signature string) string {
+	if name, ok := hypervisorNames[strings.TrimRight(signature, "\x00")]; ok {
+		return name
+	}
+	return ""
+}
+
+// getVirtExtensions returns the hardware virtualization extensions
+// supported by the cpu, as reported in /proc/cpuinfo
+func getVirtExtensions() ([]string, error) {
+	data, err := ioutil.ReadFile("/proc/cpuinfo")
+	if err != nil {
+		return nil, err
+	}
+	return parseVirtExtensions(string(data)), nil
+}
+
+// parseVirtExtensions returns the virtualization related cpu flags of
+// cpuinfo data. Since kernel 5.9 the VMX features are reported on a separate
+// "vmx flags" line.
+func parseVirtExtensions(cpuinfo string) []string {
+	known := map[string]string{"vmx": "VMX", "svm": "SVM", "ept": "EPT", "vpid": "VPID", "npt": "NPT", "flexpriority": "FLEXPRIORITY"}
+
+	ext := []string{}
+	seen := map[string]bool{}
+	for _, line := range strings.Split(cpuinfo, "\n") {
+		// The flags of the first cpu are enough
+		if strings.TrimSpace(line) == "" && len(seen) > 0 {
+			break
+		}
+		split := strings.SplitN(line, ":", 2)
+		if len(split) != 2 {
+			continue
+		}
+		if key := strings.TrimSpace(split[0]); key != "flags" && key != "vmx flags" {
+			continue
+		}
+		for _, f := range strings.Fields(split[1]) {
+			if name, ok := known[f]; ok && !seen[name] {
+				seen[name] = true
+				ext = append(ext, name)
+			}
+		}
+	}
+	return ext
+}
+
+func readSysfsString(elem ...string) (string, error) {
+	data, err := ioutil.ReadFile(source.SysfsDir.Path(elem...))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(data)), nil
+}
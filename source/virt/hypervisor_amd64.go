@@ -0,0 +1,49 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virt
+
+import (
+	"encoding/binary"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/cpuid"
+)
+
+const (
+	// CPUID EAX input values
+	LEAF_PROCESSOR_INFO = 0x01
+	LEAF_HYPERVISOR     = 0x40000000
+
+	// CPUID bitmasks
+	PROCESSOR_INFO_ECX_HYPERVISOR = 1 << 31
+)
+
+// detectHypervisor detects if running in a virtual machine and the vendor of
+// the hypervisor from cpuid. The vendor is empty if the hypervisor signature
+// is not known.
+func detectHypervisor() (string, bool) {
+	if cpuid.Cpuid(LEAF_PROCESSOR_INFO, 0).ECX&PROCESSOR_INFO_ECX_HYPERVISOR == 0 {
+		return "", false
+	}
+
+	r := cpuid.Cpuid(LEAF_HYPERVISOR, 0)
+	sig := make([]byte, 12)
+	binary.LittleEndian.PutUint32(sig[0:], r.EBX)
+	binary.LittleEndian.PutUint32(sig[4:], r.ECX)
+	binary.LittleEndian.PutUint32(sig[8:], r.EDX)
+
+	return hypervisorName(string(sig)), true
+}
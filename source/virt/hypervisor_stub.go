@@ -0,0 +1,32 @@
+//go:build !amd64
+// +build !amd64
+
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virt
+
+// detectHypervisor detects if running in a virtual machine and the vendor of
+// the hypervisor. Without cpuid, DMI and sysfs information is used instead.
+func detectHypervisor() (string, bool) {
+	if vendor, isVM := dmiHypervisor(); isVM {
+		return vendor, true
+	}
+	if v, err := readSysfsString("hypervisor/type"); err == nil && v != "" {
+		return hypervisorNames[v], true
+	}
+	return "", false
+}
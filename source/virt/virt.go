@@ -0,0 +1,146 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virt
+
+import (
+	"os"
+	"strconv"
+
+	"k8s.io/klog/v2"
+
+	"sigs.k8s.io/node-feature-discovery/pkg/api/feature"
+	"sigs.k8s.io/node-feature-discovery/pkg/utils"
+	"sigs.k8s.io/node-feature-discovery/source"
+)
+
+const Name = "virt"
+
+const (
+	ExtensionFeature = "extension"
+	KvmFeature       = "kvm"
+	PlatformFeature  = "platform"
+)
+
+// virtSource implements the FeatureSource and LabelSource interfaces.
+type virtSource struct {
+	features *feature.DomainFeatures
+}
+
+// Singleton source instance
+var (
+	src virtSource
+	_   source.FeatureSource = &src
+	_   source.LabelSource   = &src
+)
+
+// Name returns the name of the feature source
+func (s *virtSource) Name() string { return Name }
+
+// Priority method of the LabelSource interface
+func (s *virtSource) Priority() int { return 0 }
+
+// GetLabels method of the LabelSource interface
+func (s *virtSource) GetLabels() (source.FeatureLabels, error) {
+	labels := source.FeatureLabels{}
+	features := s.GetFeatures()
+
+	platform := features.Values[PlatformFeature].Elements
+	if v, ok := platform["vm"]; ok {
+		labels["platform.vm"] = v
+	}
+	if v := utils.SanitizeLabelValue(platform["hypervisor"]); v != "" {
+		labels["platform.hypervisor"] = v
+	}
+
+	kvm := features.Values[KvmFeature].Elements
+	if kvm["usable"] == "true" {
+		labels["kvm.usable"] = true
+	}
+	if kvm["nested"] == "true" {
+		labels["kvm.nested"] = true
+	}
+
+	return labels, nil
+}
+
+// Discover method of the FeatureSource interface
+func (s *virtSource) Discover() error {
+	s.features = feature.NewDomainFeatures()
+
+	// Detect if running in a virtual machine
+	platform := make(map[string]string)
+	hypervisor, isVM := detectHypervisor()
+	platform["vm"] = strconv.FormatBool(isVM)
+	if hypervisor != "" {
+		platform["hypervisor"] = hypervisor
+	}
+	s.features.Values[PlatformFeature] = feature.NewValueFeatures(platform)
+
+	// Detect kvm
+	s.features.Values[KvmFeature] = feature.NewValueFeatures(detectKvm())
+
+	// Detect virtualization extensions of the cpu
+	if ext, err := getVirtExtensions(); err != nil {
+		klog.Errorf("failed to detect virtualization extensions: %v", err)
+	} else {
+		s.features.Keys[ExtensionFeature] = feature.NewKeyFeatures(ext...)
+	}
+
+	utils.KlogDump(3, "discovered virt features:", "  ", s.features)
+
+	return nil
+}
+
+// GetFeatures method of the FeatureSource Interface
+func (s *virtSource) GetFeatures() *feature.DomainFeatures {
+	if s.features == nil {
+		s.features = feature.NewDomainFeatures()
+	}
+	return s.features
+}
+
+// detectKvm detects the availability of kvm
+func detectKvm() map[string]string {
+	kvm := make(map[string]string)
+
+	// The kvm misc device only gets registered by the vendor module if the
+	// hardware virtualization extensions are available and enabled. Checking
+	// sysfs instead of opening /dev/kvm does not require access to the device
+	// node from the nfd-worker container.
+	if _, err := os.Stat(source.SysfsDir.Path("class/misc/kvm")); err != nil {
+		klog.V(1).Infof("kvm not usable: %v", err)
+		kvm["usable"] = "false"
+	} else {
+		kvm["usable"] = "true"
+	}
+
+	for _, mod := range []string{"kvm_intel", "kvm_amd"} {
+		v, err := readSysfsString("module", mod, "parameters/nested")
+		if err != nil {
+			continue
+		}
+		kvm["module"] = mod
+		kvm["nested"] = strconv.FormatBool(v == "Y" || v == "y" || v == "1")
+		break
+	}
+
+	return kvm
+}
+
+func init() {
+	source.Register(&src)
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package virt
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"sigs.k8s.io/node-feature-discovery/source/testutils"
+)
+
+func TestVirtSource(t *testing.T) {
+	assert.Equal(t, src.Name(), Name)
+
+	// Check that GetLabels works with empty features
+	src.features = nil
+	l, err := src.GetLabels()
+
+	assert.Nil(t, err, err)
+	assert.Empty(t, l)
+}
+
+func TestHypervisorName(t *testing.T) {
+	assert.Equal(t, "KVM", hypervisorName("KVMKVMKVM\x00\x00\x00"))
+	assert.Equal(t, "Microsoft", hypervisorName("Microsoft Hv"))
+	assert.Equal(t, "", hypervisorName("FooBarFooBar"))
+}
+
+func TestDetectKvm(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+	testutils.WriteFiles(t, sysfs, map[string]string{"module/kvm_amd/parameters/nested": "1"})
+
+	expected := map[string]string{"usable": "false", "module": "kvm_amd", "nested": "true"}
+	assert.Equal(t, expected, detectKvm())
+
+	assert.Nil(t, os.MkdirAll(filepath.Join(sysfs, "class/misc/kvm"), 0755))
+	expected["usable"] = "true"
+	assert.Equal(t, expected, detectKvm())
+}
+
+func TestParseVirtExtensions(t *testing.T) {
+	cpuinfo := "processor\t: 0\nflags\t\t: fpu vme vmx ssse3 ept vpid\n\nprocessor\t: 1\nflags\t\t: fpu svm\n"
+	assert.Equal(t, []string{"VMX", "EPT", "VPID"}, parseVirtExtensions(cpuinfo))
+
+	// Kernel 5.9+ reports vmx features on a separate line
+	cpuinfo = "processor\t: 0\nflags\t\t: fpu vme vmx ssse3\nvmx flags\t: vnmi preemption_timer invvpid ept_x_only flexpriority tsc_offset ept vpid unrestricted_guest\nbugs\t\t: spectre_v1\n\nprocessor\t: 1\nflags\t\t: fpu svm\n"
+	assert.Equal(t, []string{"VMX", "FLEXPRIORITY", "EPT", "VPID"}, parseVirtExtensions(cpuinfo))
+}
+
+func TestDmiHypervisor(t *testing.T) {
+	sysfs := testutils.FakeSysfs(t)
+	writeDmi := func(attrs map[string]string) {
+		dir := filepath.Join(sysfs, "class/dmi/id")
+		assert.Nil(t, os.RemoveAll(dir))
+		testutils.WriteFiles(t, dir, attrs)
+	}
+
+	// Bare metal EC2 instance
+	writeDmi(map[string]string{"sys_vendor": "Amazon EC2", "product_name": "m5.metal", "bios_vendor": "Amazon EC2"})
+	vendor, isVM := dmiHypervisor()
+	assert.False(t, isVM)
+	assert.Equal(t, "", vendor)
+
+	// QEMU/KVM guest
+	writeDmi(map[string]string{"sys_vendor": "QEMU", "product_name": "Standard PC (Q35 + ICH9, 2009)", "bios_vendor": "SeaBIOS"})
+	vendor, isVM = dmiHypervisor()
+	assert.True(t, isVM)
+	assert.Equal(t, "QEMU", vendor)
+}